@@ -7,28 +7,159 @@ import (
 	"time"
 )
 
+// Defines values for CodeHostSyncStatus.
+const (
+	CodeHostSyncStatusFAILED  CodeHostSyncStatus = "FAILED"
+	CodeHostSyncStatusPENDING CodeHostSyncStatus = "PENDING"
+	CodeHostSyncStatusSKIPPED CodeHostSyncStatus = "SKIPPED"
+	CodeHostSyncStatusSYNCED  CodeHostSyncStatus = "SYNCED"
+)
+
 // Defines values for ErrorResponseErrorCode.
 const (
-	NOCANDIDATE ErrorResponseErrorCode = "NO_CANDIDATE"
-	NOTASSIGNED ErrorResponseErrorCode = "NOT_ASSIGNED"
-	NOTFOUND    ErrorResponseErrorCode = "NOT_FOUND"
-	PREXISTS    ErrorResponseErrorCode = "PR_EXISTS"
-	PRMERGED    ErrorResponseErrorCode = "PR_MERGED"
-	TEAMEXISTS  ErrorResponseErrorCode = "TEAM_EXISTS"
+	AUTHORINACTIVE     ErrorResponseErrorCode = "AUTHOR_INACTIVE"
+	INVALIDREQUEST     ErrorResponseErrorCode = "INVALID_REQUEST"
+	INVALIDSETTINGS    ErrorResponseErrorCode = "INVALID_SETTINGS"
+	INVALIDSIGNATURE   ErrorResponseErrorCode = "INVALID_SIGNATURE"
+	NOCANDIDATE        ErrorResponseErrorCode = "NO_CANDIDATE"
+	NOTASSIGNED        ErrorResponseErrorCode = "NOT_ASSIGNED"
+	NOTENOUGHAPPROVALS ErrorResponseErrorCode = "NOT_ENOUGH_APPROVALS"
+	NOTENOUGHREVIEWERS ErrorResponseErrorCode = "NOT_ENOUGH_REVIEWERS"
+	NOTFOUND           ErrorResponseErrorCode = "NOT_FOUND"
+	PRCLOSED           ErrorResponseErrorCode = "PR_CLOSED"
+	PREXISTS           ErrorResponseErrorCode = "PR_EXISTS"
+	PRMERGED           ErrorResponseErrorCode = "PR_MERGED"
+	TEAMEXISTS         ErrorResponseErrorCode = "TEAM_EXISTS"
+	TEAMHASOPENREVIEWS ErrorResponseErrorCode = "TEAM_HAS_OPEN_REVIEWS"
+)
+
+// Defines values for GitHubDeliveryResultStatus.
+const (
+	Duplicate GitHubDeliveryResultStatus = "duplicate"
+	Ignored   GitHubDeliveryResultStatus = "ignored"
+	Processed GitHubDeliveryResultStatus = "processed"
+)
+
+// Defines values for HealthCheckStatus.
+const (
+	HealthCheckStatusDisabled HealthCheckStatus = "disabled"
+	HealthCheckStatusFail     HealthCheckStatus = "fail"
+	HealthCheckStatusOk       HealthCheckStatus = "ok"
 )
 
 // Defines values for PullRequestStatus.
 const (
+	PullRequestStatusCLOSED PullRequestStatus = "CLOSED"
 	PullRequestStatusMERGED PullRequestStatus = "MERGED"
 	PullRequestStatusOPEN   PullRequestStatus = "OPEN"
 )
 
+// Defines values for PullRequestEventEventType.
+const (
+	PullRequestEventEventTypeASSIGNED      PullRequestEventEventType = "ASSIGNED"
+	PullRequestEventEventTypeCLOSED        PullRequestEventEventType = "CLOSED"
+	PullRequestEventEventTypeCREATED       PullRequestEventEventType = "CREATED"
+	PullRequestEventEventTypeMERGED        PullRequestEventEventType = "MERGED"
+	PullRequestEventEventTypeNEEDSREVIEWER PullRequestEventEventType = "NEEDS_REVIEWER"
+	PullRequestEventEventTypeREASSIGNED    PullRequestEventEventType = "REASSIGNED"
+	PullRequestEventEventTypeREMINDED      PullRequestEventEventType = "REMINDED"
+	PullRequestEventEventTypeREOPENED      PullRequestEventEventType = "REOPENED"
+	PullRequestEventEventTypeREVIEWED      PullRequestEventEventType = "REVIEWED"
+)
+
+// Defines values for PullRequestEventFromStatus.
+const (
+	PullRequestEventFromStatusCLOSED PullRequestEventFromStatus = "CLOSED"
+	PullRequestEventFromStatusMERGED PullRequestEventFromStatus = "MERGED"
+	PullRequestEventFromStatusOPEN   PullRequestEventFromStatus = "OPEN"
+)
+
+// Defines values for PullRequestEventToStatus.
+const (
+	PullRequestEventToStatusCLOSED PullRequestEventToStatus = "CLOSED"
+	PullRequestEventToStatusMERGED PullRequestEventToStatus = "MERGED"
+	PullRequestEventToStatusOPEN   PullRequestEventToStatus = "OPEN"
+)
+
 // Defines values for PullRequestShortStatus.
 const (
-	PullRequestShortStatusMERGED PullRequestShortStatus = "MERGED"
-	PullRequestShortStatusOPEN   PullRequestShortStatus = "OPEN"
+	CLOSED PullRequestShortStatus = "CLOSED"
+	MERGED PullRequestShortStatus = "MERGED"
+	OPEN   PullRequestShortStatus = "OPEN"
+)
+
+// Defines values for ReadinessStatus.
+const (
+	ReadinessStatusFail ReadinessStatus = "fail"
+	ReadinessStatusOk   ReadinessStatus = "ok"
+)
+
+// Defines values for ReviewVerdict.
+const (
+	APPROVED         ReviewVerdict = "APPROVED"
+	CHANGESREQUESTED ReviewVerdict = "CHANGES_REQUESTED"
+	COMMENTED        ReviewVerdict = "COMMENTED"
+)
+
+// Defines values for TeamSettingsStrategy.
+const (
+	LeastLoaded  TeamSettingsStrategy = "least_loaded"
+	Random       TeamSettingsStrategy = "random"
+	RoundRobin   TeamSettingsStrategy = "round_robin"
+	Weighted     TeamSettingsStrategy = "weighted"
+	WorkingHours TeamSettingsStrategy = "working_hours"
+)
+
+// Defines values for WebhookDeliveryStatus.
+const (
+	WebhookDeliveryStatusDELIVERED WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusFAILED    WebhookDeliveryStatus = "FAILED"
+	WebhookDeliveryStatusPENDING   WebhookDeliveryStatus = "PENDING"
+)
+
+// Defines values for PostTeamDeleteJSONBodyPolicy.
+const (
+	MoveMembers         PostTeamDeleteJSONBodyPolicy = "move_members"
+	RefuseIfOpenReviews PostTeamDeleteJSONBodyPolicy = "refuse_if_open_reviews"
+)
+
+// Defines values for GetWebhooksDeliveriesParamsStatus.
+const (
+	DELIVERED GetWebhooksDeliveriesParamsStatus = "DELIVERED"
+	FAILED    GetWebhooksDeliveriesParamsStatus = "FAILED"
+	PENDING   GetWebhooksDeliveriesParamsStatus = "PENDING"
 )
 
+// CodeHostSync defines model for CodeHostSync.
+type CodeHostSync struct {
+	Attempts      int       `json:"attempts"`
+	LastError     *string   `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	PullRequestId string    `json:"pull_request_id"`
+
+	// Status SKIPPED — PR создан не из GitHub, FAILED — попытки исчерпаны или GitHub отклонил запрос
+	Status   CodeHostSyncStatus `json:"status"`
+	SyncedAt *time.Time         `json:"synced_at,omitempty"`
+
+	// SyncedReviewers Логины GitHub, у которых ревью запрошено последней успешной синхронизацией
+	SyncedReviewers []string  `json:"synced_reviewers"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CodeHostSyncStatus SKIPPED — PR создан не из GitHub, FAILED — попытки исчерпаны или GitHub отклонил запрос
+type CodeHostSyncStatus string
+
+// DeactivationReport defines model for DeactivationReport.
+type DeactivationReport struct {
+	// AlreadyInactive user_id, которые уже были неактивны (повторный запрос)
+	AlreadyInactive []string `json:"already_inactive"`
+
+	// Deactivated user_id, деактивированные этим запросом
+	Deactivated   []string             `json:"deactivated"`
+	Reassignments []ReviewReassignment `json:"reassignments"`
+	TeamName      string               `json:"team_name"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error struct {
@@ -40,21 +171,153 @@ type ErrorResponse struct {
 // ErrorResponseErrorCode defines model for ErrorResponse.Error.Code.
 type ErrorResponseErrorCode string
 
+// GitHubDeliveryResult defines model for GitHubDeliveryResult.
+type GitHubDeliveryResult struct {
+	Action     *string `json:"action,omitempty"`
+	DeliveryId string  `json:"delivery_id"`
+
+	// PullRequestId Идентификатор PR в сервисе, `<owner>/<repo>#<number>`
+	PullRequestId *string `json:"pull_request_id,omitempty"`
+
+	// Reason Почему событие проигнорировано
+	Reason *string `json:"reason,omitempty"`
+
+	// Status duplicate — доставка с этим X-GitHub-Delivery уже была обработана
+	Status GitHubDeliveryResultStatus `json:"status"`
+}
+
+// GitHubDeliveryResultStatus duplicate — доставка с этим X-GitHub-Delivery уже была обработана
+type GitHubDeliveryResultStatus string
+
+// GitHubUser defines model for GitHubUser.
+type GitHubUser struct {
+	// Login Логин на GitHub, хранится в нижнем регистре
+	Login  string `json:"login"`
+	UserId string `json:"user_id"`
+}
+
+// HealthCheck defines model for HealthCheck.
+type HealthCheck struct {
+	// LastRunAt Время последнего прохода, только для фоновых воркеров
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+
+	// Message Причина отказа или ошибка последнего прохода воркера
+	Message *string           `json:"message,omitempty"`
+	Name    string            `json:"name"`
+	Status  HealthCheckStatus `json:"status"`
+}
+
+// HealthCheckStatus defines model for HealthCheck.Status.
+type HealthCheckStatus string
+
+// Latency defines model for Latency.
+type Latency struct {
+	// AssignmentToMerge Перцентили длительности в секундах, null если измерять нечего
+	AssignmentToMerge *LatencyPercentiles `json:"assignment_to_merge"`
+
+	// Assignments Назначения ревьюверов в этих PR
+	Assignments int `json:"assignments"`
+
+	// MergedPrs Смёрженные PR
+	MergedPrs int `json:"merged_prs"`
+
+	// TimeToMerge Перцентили длительности в секундах, null если измерять нечего
+	TimeToMerge *LatencyPercentiles `json:"time_to_merge"`
+}
+
+// LatencyPercentiles Перцентили длительности в секундах, null если измерять нечего
+type LatencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// LatencyReport defines model for LatencyReport.
+type LatencyReport struct {
+	From      time.Time         `json:"from"`
+	Overall   Latency           `json:"overall"`
+	Reviewers []ReviewerLatency `json:"reviewers"`
+	Teams     []TeamLatency     `json:"teams"`
+	To        time.Time         `json:"to"`
+
+	// Weekly Недели без смёрженных PR пропускаются
+	Weekly []WeeklyLatency `json:"weekly"`
+}
+
+// MovedMember defines model for MovedMember.
+type MovedMember struct {
+	FromTeam string `json:"from_team"`
+	UserId   string `json:"user_id"`
+}
+
+// OverdueReview defines model for OverdueReview.
+type OverdueReview struct {
+	AssignedAt      time.Time `json:"assigned_at"`
+	AuthorId        string    `json:"author_id"`
+	DueAt           time.Time `json:"due_at"`
+	PullRequestId   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+	ReviewerId      string    `json:"reviewer_id"`
+	ReviewerTeam    *string   `json:"reviewer_team,omitempty"`
+}
+
 // PullRequest defines model for PullRequest.
 type PullRequest struct {
 	// AssignedReviewers user_id назначенных ревьюверов (0..2)
-	AssignedReviewers []string          `json:"assigned_reviewers"`
-	AuthorId          string            `json:"author_id"`
-	CreatedAt         *time.Time        `json:"createdAt"`
-	MergedAt          *time.Time        `json:"mergedAt"`
-	PullRequestId     string            `json:"pull_request_id"`
-	PullRequestName   string            `json:"pull_request_name"`
-	Status            PullRequestStatus `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	AuthorId          string     `json:"author_id"`
+	ClosedAt          *time.Time `json:"closedAt"`
+	CreatedAt         *time.Time `json:"createdAt"`
+
+	// Labels Метки PR в нижнем регистре
+	Labels   *[]string  `json:"labels,omitempty"`
+	MergedAt *time.Time `json:"mergedAt"`
+
+	// NeedsReviewer Ревьювер был деактивирован, а замену найти не удалось
+	NeedsReviewer   *bool  `json:"needs_reviewer,omitempty"`
+	PullRequestId   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+
+	// Reviewers Подробности по каждому назначенному ревьюверу
+	Reviewers *[]ReviewerAssignment `json:"reviewers,omitempty"`
+	Status    PullRequestStatus     `json:"status"`
 }
 
 // PullRequestStatus defines model for PullRequest.Status.
 type PullRequestStatus string
 
+// PullRequestEvent defines model for PullRequestEvent.
+type PullRequestEvent struct {
+	// Actor Значение заголовка X-Actor запроса, вызвавшего событие (system:reminder для фонового воркера)
+	Actor      *string                     `json:"actor,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	EventId    int64                       `json:"event_id"`
+	EventType  PullRequestEventEventType   `json:"event_type"`
+	FromStatus *PullRequestEventFromStatus `json:"from_status,omitempty"`
+
+	// OldReviewerId Заменённый ревьювер (REASSIGNED)
+	OldReviewerId *string `json:"old_reviewer_id,omitempty"`
+
+	// Reason Причина назначения или замены (manual, reviewer_deactivated, reviewer_inactive_on_reopen, fallback_team, escalated)
+	Reason *string `json:"reason,omitempty"`
+
+	// ReviewerId Назначенный ревьювер (для REASSIGNED — новый)
+	ReviewerId *string                   `json:"reviewer_id,omitempty"`
+	ToStatus   *PullRequestEventToStatus `json:"to_status,omitempty"`
+
+	// Verdict Последний вердикт ревьювера
+	Verdict *ReviewVerdict `json:"verdict,omitempty"`
+}
+
+// PullRequestEventEventType defines model for PullRequestEvent.EventType.
+type PullRequestEventEventType string
+
+// PullRequestEventFromStatus defines model for PullRequestEvent.FromStatus.
+type PullRequestEventFromStatus string
+
+// PullRequestEventToStatus defines model for PullRequestEvent.ToStatus.
+type PullRequestEventToStatus string
+
 // PullRequestShort defines model for PullRequestShort.
 type PullRequestShort struct {
 	AuthorId        string                 `json:"author_id"`
@@ -66,17 +329,218 @@ type PullRequestShort struct {
 // PullRequestShortStatus defines model for PullRequestShort.Status.
 type PullRequestShortStatus string
 
+// Readiness defines model for Readiness.
+type Readiness struct {
+	Checks []HealthCheck   `json:"checks"`
+	Status ReadinessStatus `json:"status"`
+}
+
+// ReadinessStatus defines model for Readiness.Status.
+type ReadinessStatus string
+
+// ReviewReassignment defines model for ReviewReassignment.
+type ReviewReassignment struct {
+	// IsFallback Новый ревьювер взят из резервной команды
+	IsFallback *bool `json:"is_fallback,omitempty"`
+
+	// MatchedTags Навыки нового ревьювера, совпавшие с метками PR
+	MatchedTags *[]string `json:"matched_tags,omitempty"`
+
+	// NeedsReviewer Кандидата не нашлось, PR помечен как требующий ревьювера
+	NeedsReviewer bool `json:"needs_reviewer"`
+
+	// NewReviewerId user_id нового ревьювера, null если кандидата не нашлось
+	NewReviewerId *string `json:"new_reviewer_id"`
+
+	// NewReviewerTeam Команда нового ревьювера
+	NewReviewerTeam *string `json:"new_reviewer_team"`
+	OldReviewerId   string  `json:"old_reviewer_id"`
+	PullRequestId   string  `json:"pull_request_id"`
+}
+
+// ReviewVerdict Последний вердикт ревьювера
+type ReviewVerdict string
+
+// ReviewerAssignment defines model for ReviewerAssignment.
+type ReviewerAssignment struct {
+	AssignedAt *time.Time `json:"assigned_at"`
+
+	// DueAt Срок начала ревью по SLA команды автора, считается в рабочих часах ревьювера
+	DueAt *time.Time `json:"due_at"`
+
+	// IsCodeOwner Ревьювер выбран, потому что по CODEOWNERS ему принадлежат изменённые файлы
+	IsCodeOwner bool `json:"is_code_owner"`
+
+	// IsFallback Ревьювер взят из резервной команды, потому что в команде автора не хватило кандидатов
+	IsFallback bool `json:"is_fallback"`
+
+	// IsOverdue PR открыт, срок прошёл, а ревьювер не оставил ни одного ревью
+	IsOverdue *bool `json:"is_overdue,omitempty"`
+
+	// MatchedTags Навыки ревьювера, совпавшие с метками PR
+	MatchedTags *[]string `json:"matched_tags,omitempty"`
+	TeamName    *string   `json:"team_name,omitempty"`
+	UserId      string    `json:"user_id"`
+
+	// Verdict Последний вердикт ревьювера
+	Verdict *ReviewVerdict `json:"verdict,omitempty"`
+
+	// VerdictAt Время последнего вердикта ревьювера
+	VerdictAt *time.Time `json:"verdict_at"`
+}
+
+// ReviewerLatency defines model for ReviewerLatency.
+type ReviewerLatency struct {
+	// AssignmentToMerge Перцентили длительности в секундах, null если измерять нечего
+	AssignmentToMerge *LatencyPercentiles `json:"assignment_to_merge"`
+
+	// Assignments Назначения ревьюверов в этих PR
+	Assignments int `json:"assignments"`
+
+	// MergedPrs Смёрженные PR
+	MergedPrs  int    `json:"merged_prs"`
+	ReviewerId string `json:"reviewer_id"`
+
+	// TeamName Команда ревьювера
+	TeamName string `json:"team_name"`
+
+	// TimeToMerge Перцентили длительности в секундах, null если измерять нечего
+	TimeToMerge *LatencyPercentiles `json:"time_to_merge"`
+}
+
+// SLACompliance defines model for SLACompliance.
+type SLACompliance struct {
+	// CompliancePercent Доля within_sla от total в процентах, null если назначений не было
+	CompliancePercent *float64            `json:"compliance_percent"`
+	From              time.Time           `json:"from"`
+	Teams             []TeamSLACompliance `json:"teams"`
+	To                time.Time           `json:"to"`
+
+	// Total Назначений с SLA в PR, смёрженных за период
+	Total int `json:"total"`
+
+	// WithinSla Из них первое ревью пришло до срока
+	WithinSla int `json:"within_sla"`
+}
+
 // Team defines model for Team.
 type Team struct {
-	Members  []TeamMember `json:"members"`
-	TeamName string       `json:"team_name"`
+	Members []TeamMember `json:"members"`
+
+	// Rotation user_id активных участников в порядке очереди round-robin (первый получит следующее ревью)
+	Rotation *[]string `json:"rotation,omitempty"`
+	TeamName string    `json:"team_name"`
+}
+
+// TeamCodeOwners defines model for TeamCodeOwners.
+type TeamCodeOwners struct {
+	// Content Содержимое файла CODEOWNERS
+	Content string `json:"content"`
+
+	// Repository Репозиторий в виде `<owner>/<repo>`
+	Repository string     `json:"repository"`
+	TeamName   string     `json:"team_name"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// TeamFallbacks defines model for TeamFallbacks.
+type TeamFallbacks struct {
+	// FallbackTeams Резервные команды в порядке приоритета
+	FallbackTeams []string `json:"fallback_teams"`
+	TeamName      string   `json:"team_name"`
+}
+
+// TeamLatency defines model for TeamLatency.
+type TeamLatency struct {
+	// AssignmentToMerge Перцентили длительности в секундах, null если измерять нечего
+	AssignmentToMerge *LatencyPercentiles `json:"assignment_to_merge"`
+
+	// Assignments Назначения ревьюверов в этих PR
+	Assignments int `json:"assignments"`
+
+	// MergedPrs Смёрженные PR
+	MergedPrs int `json:"merged_prs"`
+
+	// TeamName Команда автора
+	TeamName string `json:"team_name"`
+
+	// TimeToMerge Перцентили длительности в секундах, null если измерять нечего
+	TimeToMerge *LatencyPercentiles `json:"time_to_merge"`
 }
 
 // TeamMember defines model for TeamMember.
 type TeamMember struct {
-	IsActive bool   `json:"is_active"`
-	UserId   string `json:"user_id"`
-	Username string `json:"username"`
+	Absence  *UserAbsence `json:"absence,omitempty"`
+	IsActive bool         `json:"is_active"`
+
+	// IsAvailable Пользователь не в отсутствии прямо сейчас (только в ответах)
+	IsAvailable *bool  `json:"is_available,omitempty"`
+	UserId      string `json:"user_id"`
+	Username    string `json:"username"`
+}
+
+// TeamSLACompliance defines model for TeamSLACompliance.
+type TeamSLACompliance struct {
+	CompliancePercent *float64 `json:"compliance_percent"`
+
+	// TeamName Команда ревьювера
+	TeamName  string `json:"team_name"`
+	Total     int    `json:"total"`
+	WithinSla int    `json:"within_sla"`
+}
+
+// TeamSettings defines model for TeamSettings.
+type TeamSettings struct {
+	// AbsenceLookaheadHours Не назначать тех, чьё отсутствие начнётся в ближайшие N часов (0 — только текущие отсутствия)
+	AbsenceLookaheadHours *int `json:"absence_lookahead_hours,omitempty"`
+
+	// AllowInactiveAuthor Может ли неактивный пользователь открывать PR
+	AllowInactiveAuthor bool `json:"allow_inactive_author"`
+
+	// MaxReviewers Сколько ревьюверов назначать на PR
+	MaxReviewers int `json:"max_reviewers"`
+
+	// MinReviewers Минимальное число ревьюверов, иначе создание PR падает с NOT_ENOUGH_REVIEWERS
+	MinReviewers int `json:"min_reviewers"`
+
+	// RequiredApprovals Сколько назначенных ревьюверов должны одобрить PR перед merge (0 — проверка отключена)
+	RequiredApprovals *int `json:"required_approvals,omitempty"`
+
+	// ReviewSlaHours За сколько рабочих часов ревьювера нужно начать ревью (0 — SLA не отслеживается)
+	ReviewSlaHours *int `json:"review_sla_hours,omitempty"`
+
+	// Strategy Стратегия выбора ревьюверов, если не задана — берётся из конфига сервиса. `working_hours` сначала
+	// выбирает тех, кто сейчас в рабочих часах (/users/schedule/set), затем тех, у кого они начнутся раньше.
+	Strategy *TeamSettingsStrategy `json:"strategy,omitempty"`
+	TeamName string                `json:"team_name"`
+}
+
+// TeamSettingsStrategy Стратегия выбора ревьюверов, если не задана — берётся из конфига сервиса. `working_hours` сначала
+// выбирает тех, кто сейчас в рабочих часах (/users/schedule/set), затем тех, у кого они начнутся раньше.
+type TeamSettingsStrategy string
+
+// TeamStatistics defines model for TeamStatistics.
+type TeamStatistics struct {
+	ActiveMembers int `json:"active_members"`
+
+	// AvgReviewersPerPr Среднее число ревьюверов в созданных PR
+	AvgReviewersPerPr float64 `json:"avg_reviewers_per_pr"`
+
+	// MaxOpenReviews Наибольшее число открытых ревью у активного участника
+	MaxOpenReviews int `json:"max_open_reviews"`
+
+	// MergedPrs PR участников команды, смёрженные за период
+	MergedPrs int `json:"merged_prs"`
+
+	// MinOpenReviews Наименьшее число открытых ревью у активного участника
+	MinOpenReviews int `json:"min_open_reviews"`
+
+	// OpenedPrs PR, созданные участниками команды
+	OpenedPrs int `json:"opened_prs"`
+
+	// StddevOpenReviews Стандартное отклонение числа открытых ревью по активным участникам
+	StddevOpenReviews float64 `json:"stddev_open_reviews"`
+	TeamName          string  `json:"team_name"`
 }
 
 // User defines model for User.
@@ -87,9 +551,33 @@ type User struct {
 	Username string `json:"username"`
 }
 
+// UserAbsence defines model for UserAbsence.
+type UserAbsence struct {
+	AbsenceId int64      `json:"absence_id"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	EndsAt    time.Time  `json:"ends_at"`
+	Reason    string     `json:"reason"`
+	StartsAt  time.Time  `json:"starts_at"`
+	UserId    string     `json:"user_id"`
+}
+
+// UserSkill defines model for UserSkill.
+type UserSkill struct {
+	// Level Уровень владения, необязателен
+	Level *int `json:"level"`
+
+	// Tag Тег навыка, сравнивается с метками PR без учёта регистра
+	Tag string `json:"tag"`
+}
+
 // UserStatistics defines model for UserStatistics.
 type UserStatistics struct {
-	IsActive bool `json:"is_active"`
+	// ClosedAuthoredPrs Количество закрытых без merge PR (как автор)
+	ClosedAuthoredPrs int `json:"closed_authored_prs"`
+
+	// ClosedReviewAssignments Количество назначений на закрытые без merge PR (как ревьюер)
+	ClosedReviewAssignments int  `json:"closed_review_assignments"`
+	IsActive                bool `json:"is_active"`
 
 	// MergedAuthoredPrs Количество смерженных PR (как автор)
 	MergedAuthoredPrs int `json:"merged_authored_prs"`
@@ -113,21 +601,126 @@ type UserStatistics struct {
 	Username               string `json:"username"`
 }
 
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempts       int                   `json:"attempts"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	DeliveryId     int64                 `json:"delivery_id"`
+	EventId        int64                 `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	LastError      *string               `json:"last_error,omitempty"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	PullRequestId  string                `json:"pull_request_id"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	SubscriptionId int64                 `json:"subscription_id"`
+}
+
+// WebhookDeliveryStatus defines model for WebhookDelivery.Status.
+type WebhookDeliveryStatus string
+
+// WebhookSubscription defines model for WebhookSubscription.
+type WebhookSubscription struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// EventTypes Типы событий из истории PR, пустой список — все события
+	EventTypes     []string `json:"event_types"`
+	IsActive       bool     `json:"is_active"`
+	SubscriptionId int64    `json:"subscription_id"`
+	Url            string   `json:"url"`
+}
+
+// WeeklyLatency defines model for WeeklyLatency.
+type WeeklyLatency struct {
+	// AssignmentToMerge Перцентили длительности в секундах, null если измерять нечего
+	AssignmentToMerge *LatencyPercentiles `json:"assignment_to_merge"`
+
+	// Assignments Назначения ревьюверов в этих PR
+	Assignments int `json:"assignments"`
+
+	// MergedPrs Смёрженные PR
+	MergedPrs int `json:"merged_prs"`
+
+	// TimeToMerge Перцентили длительности в секундах, null если измерять нечего
+	TimeToMerge *LatencyPercentiles `json:"time_to_merge"`
+
+	// WeekStart Начало недели (понедельник)
+	WeekStart time.Time `json:"week_start"`
+}
+
+// WorkSchedule defines model for WorkSchedule.
+type WorkSchedule struct {
+	IsWorkingNow *bool `json:"is_working_now,omitempty"`
+
+	// NextWorkingAt Ближайший момент в рабочих часах, текущее время, если пользователь работает
+	NextWorkingAt *time.Time `json:"next_working_at,omitempty"`
+
+	// Timezone Часовой пояс IANA, например `Europe/Moscow`
+	Timezone string `json:"timezone"`
+
+	// Weekdays Рабочие дни, 1 — понедельник, 7 — воскресенье
+	Weekdays []int `json:"weekdays"`
+
+	// WorkEnd Конец рабочего дня по местному времени, `HH:MM` (не позже `24:00`, позже начала)
+	WorkEnd string `json:"work_end"`
+
+	// WorkStart Начало рабочего дня по местному времени, `HH:MM`
+	WorkStart string `json:"work_start"`
+}
+
+// StatsFrom defines model for StatsFrom.
+type StatsFrom = time.Time
+
+// StatsTo defines model for StatsTo.
+type StatsTo = time.Time
+
 // TeamNameQuery defines model for TeamNameQuery.
 type TeamNameQuery = string
 
 // UserIdQuery defines model for UserIdQuery.
 type UserIdQuery = string
 
+// PostIntegrationsGithubWebhookJSONBody defines parameters for PostIntegrationsGithubWebhook.
+type PostIntegrationsGithubWebhookJSONBody = map[string]interface{}
+
+// PostIntegrationsGithubWebhookParams defines parameters for PostIntegrationsGithubWebhook.
+type PostIntegrationsGithubWebhookParams struct {
+	XGitHubEvent     string  `json:"X-GitHub-Event"`
+	XGitHubDelivery  string  `json:"X-GitHub-Delivery"`
+	XHubSignature256 *string `json:"X-Hub-Signature-256,omitempty"`
+}
+
+// PostPullRequestCloseJSONBody defines parameters for PostPullRequestClose.
+type PostPullRequestCloseJSONBody struct {
+	PullRequestId string `json:"pull_request_id"`
+}
+
 // PostPullRequestCreateJSONBody defines parameters for PostPullRequestCreate.
 type PostPullRequestCreateJSONBody struct {
-	AuthorId        string `json:"author_id"`
-	PullRequestId   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
+	AuthorId string `json:"author_id"`
+
+	// ChangedFiles Пути изменённых файлов относительно корня репозитория
+	ChangedFiles *[]string `json:"changed_files,omitempty"`
+
+	// Labels Метки PR, сравниваются с навыками ревьюверов
+	Labels          *[]string `json:"labels,omitempty"`
+	PullRequestId   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+
+	// Repository Репозиторий `<owner>/<repo>` для поиска CODEOWNERS, по умолчанию берётся из id вида `<owner>/<repo>#<number>`
+	Repository *string `json:"repository,omitempty"`
+}
+
+// GetPullRequestHistoryParams defines parameters for GetPullRequestHistory.
+type GetPullRequestHistoryParams struct {
+	PullRequestId string `form:"pull_request_id" json:"pull_request_id"`
 }
 
 // PostPullRequestMergeJSONBody defines parameters for PostPullRequestMerge.
 type PostPullRequestMergeJSONBody struct {
+	// Force Смержить без проверки одобрений
+	Force         *bool  `json:"force,omitempty"`
 	PullRequestId string `json:"pull_request_id"`
 }
 
@@ -137,16 +730,189 @@ type PostPullRequestReassignJSONBody struct {
 	PullRequestId string `json:"pull_request_id"`
 }
 
+// PostPullRequestReopenJSONBody defines parameters for PostPullRequestReopen.
+type PostPullRequestReopenJSONBody struct {
+	PullRequestId string `json:"pull_request_id"`
+}
+
+// PostPullRequestReviewJSONBody defines parameters for PostPullRequestReview.
+type PostPullRequestReviewJSONBody struct {
+	Comment       *string `json:"comment,omitempty"`
+	PullRequestId string  `json:"pull_request_id"`
+	ReviewerId    string  `json:"reviewer_id"`
+
+	// Verdict Последний вердикт ревьювера
+	Verdict ReviewVerdict `json:"verdict"`
+}
+
+// GetPullRequestSyncParams defines parameters for GetPullRequestSync.
+type GetPullRequestSyncParams struct {
+	PullRequestId string `form:"pull_request_id" json:"pull_request_id"`
+}
+
+// GetReviewsOverdueParams defines parameters for GetReviewsOverdue.
+type GetReviewsOverdueParams struct {
+	// TeamName Команда ревьювера
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+
+	// UserId Ревьювер
+	UserId *string `form:"user_id,omitempty" json:"user_id,omitempty"`
+}
+
+// GetStatsLatencyParams defines parameters for GetStatsLatency.
+type GetStatsLatencyParams struct {
+	// From Начало периода (включительно)
+	From *StatsFrom `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Конец периода (не включительно)
+	To *StatsTo `form:"to,omitempty" json:"to,omitempty"`
+
+	// TeamName Только PR авторов из этой команды
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+}
+
+// GetStatsSlaParams defines parameters for GetStatsSla.
+type GetStatsSlaParams struct {
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+	To   *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// TeamName Только ревьюверы этой команды
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+}
+
+// GetStatsTeamsParams defines parameters for GetStatsTeams.
+type GetStatsTeamsParams struct {
+	// From Начало периода (включительно)
+	From *StatsFrom `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Конец периода (не включительно)
+	To       *StatsTo `form:"to,omitempty" json:"to,omitempty"`
+	TeamName *string  `form:"team_name,omitempty" json:"team_name,omitempty"`
+}
+
+// GetStatsUsersParams defines parameters for GetStatsUsers.
+type GetStatsUsersParams struct {
+	// From Начало периода (включительно)
+	From *StatsFrom `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Конец периода (не включительно)
+	To *StatsTo `form:"to,omitempty" json:"to,omitempty"`
+
+	// TeamName Только пользователи этой команды
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+}
+
+// GetTeamCodeownersGetParams defines parameters for GetTeamCodeownersGet.
+type GetTeamCodeownersGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName   TeamNameQuery `form:"team_name" json:"team_name"`
+	Repository string        `form:"repository" json:"repository"`
+}
+
+// PostTeamDeactivateMembersJSONBody defines parameters for PostTeamDeactivateMembers.
+type PostTeamDeactivateMembersJSONBody struct {
+	// All Деактивировать всех участников команды (user_ids игнорируется)
+	All *bool `json:"all,omitempty"`
+
+	// FallbackTeams Резервные команды для ревью, которые некому передать внутри команды. Если не указаны, используются сохранённые в /team/fallbacks/set
+	FallbackTeams *[]string `json:"fallback_teams,omitempty"`
+	TeamName      string    `json:"team_name"`
+	UserIds       *[]string `json:"user_ids,omitempty"`
+}
+
+// PostTeamDeleteJSONBody defines parameters for PostTeamDelete.
+type PostTeamDeleteJSONBody struct {
+	// MoveTo Команда, в которую переносятся участники (только для move_members)
+	MoveTo   *string                      `json:"move_to,omitempty"`
+	Policy   PostTeamDeleteJSONBodyPolicy `json:"policy"`
+	TeamName string                       `json:"team_name"`
+}
+
+// PostTeamDeleteJSONBodyPolicy defines parameters for PostTeamDelete.
+type PostTeamDeleteJSONBodyPolicy string
+
+// GetTeamFallbacksGetParams defines parameters for GetTeamFallbacksGet.
+type GetTeamFallbacksGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
 // GetTeamGetParams defines parameters for GetTeamGet.
 type GetTeamGetParams struct {
 	// TeamName Уникальное имя команды
 	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
 }
 
+// PostTeamRenameJSONBody defines parameters for PostTeamRename.
+type PostTeamRenameJSONBody struct {
+	NewTeamName string `json:"new_team_name"`
+	TeamName    string `json:"team_name"`
+}
+
+// GetTeamSettingsGetParams defines parameters for GetTeamSettingsGet.
+type GetTeamSettingsGetParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
+// PostTeamUpdateJSONBody defines parameters for PostTeamUpdate.
+type PostTeamUpdateJSONBody struct {
+	AddMembers *[]TeamMember `json:"add_members,omitempty"`
+
+	// RemoveMembers user_id участников, которых нужно убрать из команды
+	RemoveMembers *[]string `json:"remove_members,omitempty"`
+	TeamName      string    `json:"team_name"`
+}
+
+// PostUsersAbsencesAddJSONBody defines parameters for PostUsersAbsencesAdd.
+type PostUsersAbsencesAddJSONBody struct {
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   *string   `json:"reason,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	UserId   string    `json:"user_id"`
+}
+
+// PostUsersAbsencesDeleteJSONBody defines parameters for PostUsersAbsencesDelete.
+type PostUsersAbsencesDeleteJSONBody struct {
+	AbsenceId int64 `json:"absence_id"`
+}
+
+// GetUsersAbsencesListParams defines parameters for GetUsersAbsencesList.
+type GetUsersAbsencesListParams struct {
+	// UserId Идентификатор пользователя
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+
+	// IncludePast Включить завершившиеся отсутствия
+	IncludePast *bool `form:"include_past,omitempty" json:"include_past,omitempty"`
+}
+
+// PostUsersAbsencesUpdateJSONBody defines parameters for PostUsersAbsencesUpdate.
+type PostUsersAbsencesUpdateJSONBody struct {
+	AbsenceId int64     `json:"absence_id"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    *string   `json:"reason,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+}
+
 // GetUsersGetReviewParams defines parameters for GetUsersGetReview.
 type GetUsersGetReviewParams struct {
 	// UserId Идентификатор пользователя
 	UserId UserIdQuery `form:"user_id" json:"user_id"`
+
+	// IncludeClosed Включить закрытые без merge PR (CLOSED)
+	IncludeClosed *bool `form:"include_closed,omitempty" json:"include_closed,omitempty"`
+}
+
+// GetUsersScheduleGetParams defines parameters for GetUsersScheduleGet.
+type GetUsersScheduleGetParams struct {
+	// UserId Идентификатор пользователя
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+}
+
+// PostUsersScheduleSetJSONBody defines parameters for PostUsersScheduleSet.
+type PostUsersScheduleSetJSONBody struct {
+	Schedule WorkSchedule `json:"schedule"`
+	UserId   string       `json:"user_id"`
 }
 
 // PostUsersSetIsActiveJSONBody defines parameters for PostUsersSetIsActive.
@@ -155,6 +921,48 @@ type PostUsersSetIsActiveJSONBody struct {
 	UserId   string `json:"user_id"`
 }
 
+// GetUsersSkillsGetParams defines parameters for GetUsersSkillsGet.
+type GetUsersSkillsGetParams struct {
+	UserId string `form:"user_id" json:"user_id"`
+}
+
+// PostUsersSkillsSetJSONBody defines parameters for PostUsersSkillsSet.
+type PostUsersSkillsSetJSONBody struct {
+	Skills []UserSkill `json:"skills"`
+	UserId string      `json:"user_id"`
+}
+
+// PostWebhooksCreateJSONBody defines parameters for PostWebhooksCreate.
+type PostWebhooksCreateJSONBody struct {
+	EventTypes *[]string `json:"event_types,omitempty"`
+	Secret     string    `json:"secret"`
+	Url        string    `json:"url"`
+}
+
+// PostWebhooksDeleteJSONBody defines parameters for PostWebhooksDelete.
+type PostWebhooksDeleteJSONBody struct {
+	SubscriptionId int64 `json:"subscription_id"`
+}
+
+// GetWebhooksDeliveriesParams defines parameters for GetWebhooksDeliveries.
+type GetWebhooksDeliveriesParams struct {
+	SubscriptionId *int64                             `form:"subscription_id,omitempty" json:"subscription_id,omitempty"`
+	Status         *GetWebhooksDeliveriesParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Limit          *int                               `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetWebhooksDeliveriesParamsStatus defines parameters for GetWebhooksDeliveries.
+type GetWebhooksDeliveriesParamsStatus string
+
+// PostIntegrationsGithubUsersSetJSONRequestBody defines body for PostIntegrationsGithubUsersSet for application/json ContentType.
+type PostIntegrationsGithubUsersSetJSONRequestBody = GitHubUser
+
+// PostIntegrationsGithubWebhookJSONRequestBody defines body for PostIntegrationsGithubWebhook for application/json ContentType.
+type PostIntegrationsGithubWebhookJSONRequestBody = PostIntegrationsGithubWebhookJSONBody
+
+// PostPullRequestCloseJSONRequestBody defines body for PostPullRequestClose for application/json ContentType.
+type PostPullRequestCloseJSONRequestBody PostPullRequestCloseJSONBody
+
 // PostPullRequestCreateJSONRequestBody defines body for PostPullRequestCreate for application/json ContentType.
 type PostPullRequestCreateJSONRequestBody PostPullRequestCreateJSONBody
 
@@ -164,8 +972,56 @@ type PostPullRequestMergeJSONRequestBody PostPullRequestMergeJSONBody
 // PostPullRequestReassignJSONRequestBody defines body for PostPullRequestReassign for application/json ContentType.
 type PostPullRequestReassignJSONRequestBody PostPullRequestReassignJSONBody
 
+// PostPullRequestReopenJSONRequestBody defines body for PostPullRequestReopen for application/json ContentType.
+type PostPullRequestReopenJSONRequestBody PostPullRequestReopenJSONBody
+
+// PostPullRequestReviewJSONRequestBody defines body for PostPullRequestReview for application/json ContentType.
+type PostPullRequestReviewJSONRequestBody PostPullRequestReviewJSONBody
+
 // PostTeamAddJSONRequestBody defines body for PostTeamAdd for application/json ContentType.
 type PostTeamAddJSONRequestBody = Team
 
+// PostTeamCodeownersSetJSONRequestBody defines body for PostTeamCodeownersSet for application/json ContentType.
+type PostTeamCodeownersSetJSONRequestBody = TeamCodeOwners
+
+// PostTeamDeactivateMembersJSONRequestBody defines body for PostTeamDeactivateMembers for application/json ContentType.
+type PostTeamDeactivateMembersJSONRequestBody PostTeamDeactivateMembersJSONBody
+
+// PostTeamDeleteJSONRequestBody defines body for PostTeamDelete for application/json ContentType.
+type PostTeamDeleteJSONRequestBody PostTeamDeleteJSONBody
+
+// PostTeamFallbacksSetJSONRequestBody defines body for PostTeamFallbacksSet for application/json ContentType.
+type PostTeamFallbacksSetJSONRequestBody = TeamFallbacks
+
+// PostTeamRenameJSONRequestBody defines body for PostTeamRename for application/json ContentType.
+type PostTeamRenameJSONRequestBody PostTeamRenameJSONBody
+
+// PostTeamSettingsSetJSONRequestBody defines body for PostTeamSettingsSet for application/json ContentType.
+type PostTeamSettingsSetJSONRequestBody = TeamSettings
+
+// PostTeamUpdateJSONRequestBody defines body for PostTeamUpdate for application/json ContentType.
+type PostTeamUpdateJSONRequestBody PostTeamUpdateJSONBody
+
+// PostUsersAbsencesAddJSONRequestBody defines body for PostUsersAbsencesAdd for application/json ContentType.
+type PostUsersAbsencesAddJSONRequestBody PostUsersAbsencesAddJSONBody
+
+// PostUsersAbsencesDeleteJSONRequestBody defines body for PostUsersAbsencesDelete for application/json ContentType.
+type PostUsersAbsencesDeleteJSONRequestBody PostUsersAbsencesDeleteJSONBody
+
+// PostUsersAbsencesUpdateJSONRequestBody defines body for PostUsersAbsencesUpdate for application/json ContentType.
+type PostUsersAbsencesUpdateJSONRequestBody PostUsersAbsencesUpdateJSONBody
+
+// PostUsersScheduleSetJSONRequestBody defines body for PostUsersScheduleSet for application/json ContentType.
+type PostUsersScheduleSetJSONRequestBody PostUsersScheduleSetJSONBody
+
 // PostUsersSetIsActiveJSONRequestBody defines body for PostUsersSetIsActive for application/json ContentType.
 type PostUsersSetIsActiveJSONRequestBody PostUsersSetIsActiveJSONBody
+
+// PostUsersSkillsSetJSONRequestBody defines body for PostUsersSkillsSet for application/json ContentType.
+type PostUsersSkillsSetJSONRequestBody PostUsersSkillsSetJSONBody
+
+// PostWebhooksCreateJSONRequestBody defines body for PostWebhooksCreate for application/json ContentType.
+type PostWebhooksCreateJSONRequestBody PostWebhooksCreateJSONBody
+
+// PostWebhooksDeleteJSONRequestBody defines body for PostWebhooksDelete for application/json ContentType.
+type PostWebhooksDeleteJSONRequestBody PostWebhooksDeleteJSONBody
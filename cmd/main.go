@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -14,9 +15,19 @@ import (
 	_ "github.com/go-chi/chi/v5/middleware"
 )
 
+// Commands run instead of the server when given as the first argument.
+const recomputeStatsCommand = "recompute-stats"
+
 func main() {
 	cfg := config.MustLoadConfig()
 
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	r := chi.NewRouter()
 
 	closeFn := app.MustConfigureApp(r, cfg)
@@ -32,3 +43,18 @@ func main() {
 
 	log.Println("Server stopped")
 }
+
+func runCommand(cfg *config.Config, command string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	switch command {
+	case recomputeStatsCommand:
+		if err := app.RecomputeUserStats(ctx, cfg); err != nil {
+			return fmt.Errorf("failed to recompute statistics: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, available: %s", command, recomputeStatsCommand)
+	}
+}
@@ -13,6 +13,8 @@ func TestGetTeam(t *testing.T) {
 	ctx := context.Background()
 	client, err := api.NewClient("http://localhost:8080")
 	require.NoError(t, err)
+	// the seed data has no absences, so every member is available
+	available := true
 	tests := []struct {
 		name    string
 		params  *api.GetTeamGetParams
@@ -27,15 +29,16 @@ func TestGetTeam(t *testing.T) {
 			wantOk: &api.Team{
 				TeamName: "backend",
 				Members: []api.TeamMember{
-					{UserId: "u3", Username: "Carol White", IsActive: true},
-					{UserId: "u4", Username: "David Brown", IsActive: false},
-					{UserId: "u5", Username: "Eve Davis", IsActive: true},
-					{UserId: "u6", Username: "Frank Miller", IsActive: true},
-					{UserId: "u7", Username: "Grace Wilson", IsActive: true},
-					{UserId: "u8", Username: "Henry Moore", IsActive: false},
-					{UserId: "u9", Username: "Ivy Taylor", IsActive: true},
-					{UserId: "u10", Username: "Jack Anderson", IsActive: true},
+					{UserId: "u3", Username: "Carol White", IsActive: true, IsAvailable: &available},
+					{UserId: "u4", Username: "David Brown", IsActive: false, IsAvailable: &available},
+					{UserId: "u5", Username: "Eve Davis", IsActive: true, IsAvailable: &available},
+					{UserId: "u6", Username: "Frank Miller", IsActive: true, IsAvailable: &available},
+					{UserId: "u7", Username: "Grace Wilson", IsActive: true, IsAvailable: &available},
+					{UserId: "u8", Username: "Henry Moore", IsActive: false, IsAvailable: &available},
+					{UserId: "u9", Username: "Ivy Taylor", IsActive: true, IsAvailable: &available},
+					{UserId: "u10", Username: "Jack Anderson", IsActive: true, IsAvailable: &available},
 				},
+				Rotation: &[]string{"u10", "u3", "u5", "u6", "u7", "u9"},
 			},
 		},
 	}
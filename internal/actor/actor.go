@@ -0,0 +1,32 @@
+// Package actor carries the identity of whoever triggered a request through the context.
+package actor
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header is the request header that names the actor.
+const Header = "X-Actor"
+
+type contextKey struct{}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// FromContext returns the actor stored in ctx, empty when the request didn't name one.
+func FromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(contextKey{}).(string)
+	return actor
+}
+
+// Middleware stores the actor from the request header in the request context.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(Header); actor != "" {
+			r = r.WithContext(WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
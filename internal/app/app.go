@@ -6,19 +6,36 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 
 	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/actor"
+	codeHostRepoPkg "github.com/doverlof/avito_help/internal/client/repo/codehost"
+	githubRepoPkg "github.com/doverlof/avito_help/internal/client/repo/github"
+	healthRepoPkg "github.com/doverlof/avito_help/internal/client/repo/health"
+	lockRepoPkg "github.com/doverlof/avito_help/internal/client/repo/lock"
 	pullRequestRepoPkg "github.com/doverlof/avito_help/internal/client/repo/pull-request"
-	statsRepoPkg "github.com/doverlof/avito_help/internal/client/repo/pull-request"
+	rotationRepoPkg "github.com/doverlof/avito_help/internal/client/repo/rotation"
 	teamRepoPkg "github.com/doverlof/avito_help/internal/client/repo/team"
 	userRepoPkg "github.com/doverlof/avito_help/internal/client/repo/user"
+	webhookRepoPkg "github.com/doverlof/avito_help/internal/client/repo/webhook"
+	"github.com/doverlof/avito_help/internal/codehost"
 
 	"github.com/doverlof/avito_help/internal/config"
 	"github.com/doverlof/avito_help/internal/handler"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/metrics"
+	"github.com/doverlof/avito_help/internal/reminder"
+	"github.com/doverlof/avito_help/internal/selector"
+	codeHostUseCasePkg "github.com/doverlof/avito_help/internal/usecase/codehost"
+	githubUseCasePkg "github.com/doverlof/avito_help/internal/usecase/github"
+	healthUseCasePkg "github.com/doverlof/avito_help/internal/usecase/health"
 	pullRequestUsecasePkg "github.com/doverlof/avito_help/internal/usecase/pull-request"
 	statsUseCasePkg "github.com/doverlof/avito_help/internal/usecase/stats"
 	teamUseCasePkg "github.com/doverlof/avito_help/internal/usecase/team"
 	userUseCasePkg "github.com/doverlof/avito_help/internal/usecase/user"
+	webhookUseCasePkg "github.com/doverlof/avito_help/internal/usecase/webhook"
+	"github.com/doverlof/avito_help/internal/webhook"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
@@ -33,31 +50,88 @@ func MustConfigureApp(r *chi.Mux, cfg *config.Config) func(ctx context.Context)
 
 	//Repos
 	teamRepo := teamRepoPkg.New(sqlClient)
-	pullRequestRepo := pullRequestRepoPkg.New(sqlClient)
+	codeHostSync := cfg.CodeHostConfig.Token != ""
+	pullRequestRepo := pullRequestRepoPkg.New(sqlClient, codeHostSync)
 	userRepo := userRepoPkg.New(sqlClient)
-	statsRepo := statsRepoPkg.New(sqlClient)
+	rotationRepo := rotationRepoPkg.New(sqlClient)
+	webhookRepo := webhookRepoPkg.New(sqlClient)
+	githubRepo := githubRepoPkg.New(sqlClient)
+	codeHostRepo := codeHostRepoPkg.New(sqlClient)
+	locker := lockRepoPkg.New(sqlClient)
+	healthRepo := healthRepoPkg.New(sqlClient)
+
+	//Metrics
+	registry := metrics.NewRegistry()
+	httpMetrics := metrics.NewHTTP(registry)
+	metrics.RegisterDBStats(registry, sqlClient)
+	metrics.RegisterOpenReviews(registry, pullRequestRepo)
+
+	//Selectors
+	selectors, err := selector.NewRegistry(cfg.SelectorConfig, pullRequestRepo, userRepo)
+	if err != nil {
+		panic(err)
+	}
 
 	//UseCases
 
-	teamUseCase := teamUseCasePkg.New(teamRepo)
-	pullRequestUseCase := pullRequestUsecasePkg.New(pullRequestRepo, userRepo)
+	pullRequestUseCase := pullRequestUsecasePkg.New(pullRequestRepo, userRepo, teamRepo, rotationRepo, selectors, metrics.NewPullRequests(registry))
+	teamUseCase := teamUseCasePkg.New(teamRepo, rotationRepo, userRepo, pullRequestUseCase)
+
+	userUseCase := userUseCasePkg.New(userRepo, pullRequestUseCase)
+	statsUseCase := statsUseCasePkg.New(pullRequestRepo)
+	webhookUseCase := webhookUseCasePkg.New(webhookRepo)
+	githubUseCase := githubUseCasePkg.New(githubRepo, pullRequestUseCase, cfg.GitHubConfig.WebhookSecret)
+	codeHostUseCase := codeHostUseCasePkg.New(codeHostRepo)
+	components := health.NewComponents()
+	healthUseCase := healthUseCasePkg.New(healthRepo, components, cfg.HealthConfig.Timeout)
+
+	//Workers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	var workers sync.WaitGroup
+	runWorker := func(component *health.Component, run func(ctx context.Context)) {
+		workers.Add(1)
+		component.Started()
+		go func() {
+			defer workers.Done()
+			defer component.Stopped()
+			run(workersCtx)
+		}()
+	}
+	dispatcherComponent := components.Add("webhook_dispatcher")
+	runWorker(dispatcherComponent, webhook.NewDispatcher(cfg.WebhookConfig, webhookRepo, dispatcherComponent).Run)
+	syncerComponent := components.Add("code_host_syncer")
+	if codeHostSync {
+		runWorker(syncerComponent, codehost.NewGitHubSyncer(cfg.CodeHostConfig, codeHostRepo, syncerComponent).Run)
+	} else {
+		syncerComponent.Disable()
+		fmt.Println("Code host token is not set, reviewers won't be synced")
+	}
+	reminderComponent := components.Add("reminder")
+	runWorker(reminderComponent, reminder.NewWorker(cfg.ReminderConfig, pullRequestRepo, pullRequestUseCase, locker, reminderComponent).Run)
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
 
-	userUseCase := userUseCasePkg.New(userRepo)
-	statsUseCase := statsUseCasePkg.New(statsRepo)
 	//Handlers
 
 	fmt.Println("Create server")
-	server := handler.New(teamUseCase, userUseCase, statsUseCase, pullRequestUseCase)
+	server := handler.New(teamUseCase, userUseCase, statsUseCase, pullRequestUseCase, webhookUseCase, githubUseCase, codeHostUseCase, healthUseCase)
 
 	//Middleware
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{cfg.AllowOrigin},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", actor.Header},
 		ExposedHeaders:   []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
+	r.Use(actor.Middleware)
+	r.Use(httpMetrics.Middleware)
+
+	r.Method(http.MethodGet, "/metrics", registry.Handler())
 
 	//HTTP handler
 	httpHandler := api.HandlerWithOptions(server, api.ChiServerOptions{
@@ -79,11 +153,34 @@ func MustConfigureApp(r *chi.Mux, cfg *config.Config) func(ctx context.Context)
 	}()
 
 	return func(ctx context.Context) {
+		if err := srv.Shutdown(ctx); err != nil {
+			fmt.Println(err)
+		}
+		stopWorkers()
+		select {
+		case <-workersDone:
+		case <-ctx.Done():
+		}
 		if err := sqlClient.Close(); err != nil {
 			fmt.Println(err)
 		}
-		if err := srv.Shutdown(ctx); err != nil {
+	}
+}
+
+// RecomputeUserStats rebuilds the user statistics counters, it backs the recompute-stats command.
+func RecomputeUserStats(ctx context.Context, cfg *config.Config) error {
+	sqlClient := initPostgresClient(&cfg.PostgresConfig)
+	defer func() {
+		if err := sqlClient.Close(); err != nil {
 			fmt.Println(err)
 		}
+	}()
+
+	statsUseCase := statsUseCasePkg.New(pullRequestRepoPkg.New(sqlClient, cfg.CodeHostConfig.Token != ""))
+	users, err := statsUseCase.RecomputeUserStats(ctx)
+	if err != nil {
+		return err
 	}
+	log.Printf("Recomputed statistics of %d users\n", users)
+	return nil
 }
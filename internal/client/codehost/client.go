@@ -0,0 +1,69 @@
+// Package codehost talks to the code host (GitHub) the pull requests live on.
+package codehost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CodeHostClient requests and withdraws reviews on the code host. Logins are code host logins.
+type CodeHostClient interface {
+	RequestReviewers(ctx context.Context, pr PullRequestRef, logins []string) error
+	RemoveReviewers(ctx context.Context, pr PullRequestRef, logins []string) error
+}
+
+// PullRequestRef points to a pull request on the code host.
+type PullRequestRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// ID is the pull request id used by the service, `<owner>/<repo>#<number>`.
+func (r PullRequestRef) ID() string {
+	return r.Owner + "/" + r.Repo + "#" + strconv.Itoa(r.Number)
+}
+
+// ParsePullRequestID parses an id built by PullRequestRef.ID. Pull requests created through the API
+// directly have arbitrary ids and don't parse.
+func ParsePullRequestID(id string) (PullRequestRef, bool) {
+	repository, number, ok := strings.Cut(id, "#")
+	if !ok {
+		return PullRequestRef{}, false
+	}
+	owner, repo, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return PullRequestRef{}, false
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return PullRequestRef{}, false
+	}
+	return PullRequestRef{Owner: owner, Repo: repo, Number: n}, true
+}
+
+// StatusError is returned for a non-2xx answer of the code host.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("code host answered %d", e.StatusCode)
+	}
+	return fmt.Sprintf("code host answered %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether retrying the request can help: rate limits and server errors.
+func (e *StatusError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	// GitHub answers 403 when the secondary rate limit is hit
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,76 @@
+package codehost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const DefaultGitHubURL = "https://api.github.com"
+
+type gitHub struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewGitHub returns a client of the GitHub REST API. baseURL is the API root, e.g. DefaultGitHubURL
+// or `https://github.example.com/api/v3` for GitHub Enterprise.
+func NewGitHub(baseURL, token string, client *http.Client) CodeHostClient {
+	return &gitHub{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+func (g *gitHub) RequestReviewers(ctx context.Context, pr PullRequestRef, logins []string) error {
+	return g.requestedReviewers(ctx, http.MethodPost, pr, logins)
+}
+
+func (g *gitHub) RemoveReviewers(ctx context.Context, pr PullRequestRef, logins []string) error {
+	return g.requestedReviewers(ctx, http.MethodDelete, pr, logins)
+}
+
+// requestedReviewers calls POST (request) or DELETE (remove) on /repos/{owner}/{repo}/pulls/{number}/requested_reviewers.
+func (g *gitHub) requestedReviewers(ctx context.Context, method string, pr PullRequestRef, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers",
+		g.baseURL, url.PathEscape(pr.Owner), url.PathEscape(pr.Repo), pr.Number)
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return &StatusError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+	return nil
+}
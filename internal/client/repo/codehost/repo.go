@@ -0,0 +1,195 @@
+package codehost
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSyncNotFound = errors.New("code host sync not found")
+)
+
+// Repo stores the code host sync queue filled by the pull request repo.
+type Repo interface {
+	Get(ctx context.Context, pullRequestID string) (model.CodeHostSync, error)
+
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.CodeHostSync, error)
+	DesiredReviewers(ctx context.Context, pullRequestID string) ([]string, error)
+	MarkSynced(ctx context.Context, pullRequestID string, attempt int, revision int64, reviewers []string) error
+	MarkSkipped(ctx context.Context, pullRequestID string, attempt int, reason string) error
+	Reschedule(ctx context.Context, pullRequestID string, attempt int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, pullRequestID string, attempt int, lastError string) error
+}
+
+type repo struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Repo {
+	return &repo{
+		sqlClient: sqlClient,
+	}
+}
+
+type codeHostSync struct {
+	PullRequestID   string         `db:"pull_request_id"`
+	Status          string         `db:"status"`
+	Revision        int64          `db:"revision"`
+	Attempts        int            `db:"attempts"`
+	SyncedReviewers pq.StringArray `db:"synced_reviewers"`
+	LastError       sql.NullString `db:"last_error"`
+	NextAttemptAt   time.Time      `db:"next_attempt_at"`
+	SyncedAt        sql.NullTime   `db:"synced_at"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+}
+
+var syncColumns = []string{
+	"pull_request_id",
+	"status",
+	"revision",
+	"attempts",
+	"synced_reviewers",
+	"last_error",
+	"next_attempt_at",
+	"synced_at",
+	"updated_at",
+}
+
+func convertSync(row codeHostSync) model.CodeHostSync {
+	return model.CodeHostSync{
+		PullRequestID:   row.PullRequestID,
+		Status:          model.CodeHostSyncStatus(row.Status),
+		Revision:        row.Revision,
+		Attempts:        row.Attempts,
+		SyncedReviewers: row.SyncedReviewers,
+		LastError:       row.LastError.String,
+		NextAttemptAt:   row.NextAttemptAt,
+		SyncedAt:        row.SyncedAt.Time,
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
+
+func (r *repo) Get(ctx context.Context, pullRequestID string) (model.CodeHostSync, error) {
+	query, args, err := sq.Select(syncColumns...).From("code_host_syncs").
+		Where(sq.Eq{"pull_request_id": pullRequestID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.CodeHostSync{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	var row codeHostSync
+	if err = r.sqlClient.GetContext(ctx, &row, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CodeHostSync{}, ErrSyncNotFound
+		}
+		return model.CodeHostSync{}, fmt.Errorf("failed to get code host sync: %w", err)
+	}
+	return convertSync(row), nil
+}
+
+// claimSyncs leases due syncs the same way webhook deliveries are leased.
+const claimSyncs = `
+	UPDATE code_host_syncs c
+	SET attempts = c.attempts + 1,
+	    next_attempt_at = now() + make_interval(secs => $2)
+	WHERE c.pull_request_id IN (
+		SELECT pull_request_id
+		FROM code_host_syncs
+		WHERE status = 'PENDING' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING %s
+`
+
+func (r *repo) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.CodeHostSync, error) {
+	query := fmt.Sprintf(claimSyncs, strings.Join(syncColumns, ", "))
+	var rows []codeHostSync
+	if err := r.sqlClient.SelectContext(ctx, &rows, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim code host syncs: %w", err)
+	}
+	res := make([]model.CodeHostSync, len(rows))
+	for i, row := range rows {
+		res[i] = convertSync(row)
+	}
+	return res, nil
+}
+
+// DesiredReviewers returns the code host logins of the currently assigned reviewers.
+// Reviewers without a linked login can't be requested and are left out.
+func (r *repo) DesiredReviewers(ctx context.Context, pullRequestID string) ([]string, error) {
+	query, args, err := sq.Select("gu.login").
+		From("pr_reviewers prr").
+		Join("github_users gu ON gu.user_id = prr.reviewer_id").
+		Where(sq.Eq{"prr.pull_request_id": pullRequestID}).
+		OrderBy("gu.login").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	logins := make([]string, 0)
+	if err = r.sqlClient.SelectContext(ctx, &logins, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get reviewers to sync: %w", err)
+	}
+	return logins, nil
+}
+
+// MarkSynced stores what was pushed. If the assignment changed since the sync was claimed,
+// the row stays PENDING and is picked up again right away.
+func (r *repo) MarkSynced(ctx context.Context, pullRequestID string, attempt int, revision int64, reviewers []string) error {
+	current := sq.Eq{"revision": revision}
+	return r.update(ctx, pullRequestID, attempt, map[string]interface{}{
+		"synced_reviewers": pq.Array(reviewers),
+		"synced_at":        time.Now(),
+		"last_error":       nil,
+		"status":           sq.Case().When(current, "'SYNCED'").Else("'PENDING'"),
+		"attempts":         sq.Case().When(current, "attempts").Else("0"),
+		"next_attempt_at":  time.Now(),
+	})
+}
+
+func (r *repo) MarkSkipped(ctx context.Context, pullRequestID string, attempt int, reason string) error {
+	return r.update(ctx, pullRequestID, attempt, map[string]interface{}{
+		"status":     model.SyncSkipped,
+		"last_error": reason,
+	})
+}
+
+func (r *repo) Reschedule(ctx context.Context, pullRequestID string, attempt int, lastError string, nextAttemptAt time.Time) error {
+	return r.update(ctx, pullRequestID, attempt, map[string]interface{}{
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	})
+}
+
+func (r *repo) MarkFailed(ctx context.Context, pullRequestID string, attempt int, lastError string) error {
+	return r.update(ctx, pullRequestID, attempt, map[string]interface{}{
+		"status":     model.SyncFailed,
+		"last_error": lastError,
+	})
+}
+
+// update reports the outcome of the claim that counted attempt, see the webhook repo.
+func (r *repo) update(ctx context.Context, pullRequestID string, attempt int, values map[string]interface{}) error {
+	values["updated_at"] = time.Now()
+	query, args, err := sq.Update("code_host_syncs").SetMap(values).
+		Where(sq.Eq{"pull_request_id": pullRequestID, "attempts": attempt, "status": model.SyncPending}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = r.sqlClient.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update code host sync %s: %w", pullRequestID, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrLoginNotFound = errors.New("github login is not linked")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUserLinked    = errors.New("user is linked to another github login")
+)
+
+type Repo interface {
+	SetUser(ctx context.Context, user model.GitHubUser) error
+	ListUsers(ctx context.Context) ([]model.GitHubUser, error)
+	GetUserID(ctx context.Context, login string) (string, error)
+
+	ClaimDelivery(ctx context.Context, delivery model.GitHubDelivery, action string) (bool, error)
+	ReleaseDelivery(ctx context.Context, deliveryID string) error
+}
+
+type repo struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Repo {
+	return &repo{
+		sqlClient: sqlClient,
+	}
+}
+
+// SetUser links the login to the user, replacing the previous link of the login.
+func (r *repo) SetUser(ctx context.Context, user model.GitHubUser) error {
+	query, args, err := sq.Insert("github_users").Columns(
+		"login",
+		"user_id",
+	).Values(
+		user.Login,
+		user.UserID,
+	).Suffix("ON CONFLICT (login) DO UPDATE SET user_id = EXCLUDED.user_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = r.sqlClient.ExecContext(ctx, query, args...); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			switch pgErr.Code {
+			case "23503":
+				return ErrUserNotFound
+			case "23505":
+				return ErrUserLinked
+			}
+		}
+		return fmt.Errorf("failed to link github login: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) ListUsers(ctx context.Context) ([]model.GitHubUser, error) {
+	query, args, err := sq.Select("login", "user_id").From("github_users").
+		OrderBy("login").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []struct {
+		Login  string `db:"login"`
+		UserID string `db:"user_id"`
+	}
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list github logins: %w", err)
+	}
+	res := make([]model.GitHubUser, len(rows))
+	for i, row := range rows {
+		res[i] = model.GitHubUser{Login: row.Login, UserID: row.UserID}
+	}
+	return res, nil
+}
+
+func (r *repo) GetUserID(ctx context.Context, login string) (string, error) {
+	query, args, err := sq.Select("user_id").From("github_users").
+		Where(sq.Eq{"login": login}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return "", repo2.ErrToCreateToCreateSql(err)
+	}
+	var userID string
+	err = r.sqlClient.GetContext(ctx, &userID, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrLoginNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get github login: %w", err)
+	}
+	return userID, nil
+}
+
+// ClaimDelivery records the delivery id and reports false when it was already recorded.
+func (r *repo) ClaimDelivery(ctx context.Context, delivery model.GitHubDelivery, action string) (bool, error) {
+	query, args, err := sq.Insert("github_deliveries").Columns(
+		"delivery_id",
+		"event",
+		"action",
+	).Values(
+		delivery.ID,
+		delivery.Event,
+		action,
+	).Suffix("ON CONFLICT (delivery_id) DO NOTHING").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return false, repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := r.sqlClient.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to record github delivery: %w", err)
+	}
+	v, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return v == 1, nil
+}
+
+// ReleaseDelivery forgets a delivery that failed, so that GitHub can redeliver it.
+func (r *repo) ReleaseDelivery(ctx context.Context, deliveryID string) error {
+	query, args, err := sq.Delete("github_deliveries").
+		Where(sq.Eq{"delivery_id": deliveryID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = r.sqlClient.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release github delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SchemaVersion is the version of the last file in migrations/, bump it together with a new migration.
+const SchemaVersion = 22
+
+type Repo interface {
+	Ping(ctx context.Context) error
+	// GetSchemaVersion returns the latest version recorded in schema_migrations.
+	GetSchemaVersion(ctx context.Context) (int, error)
+}
+
+type repo struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Repo {
+	return &repo{
+		sqlClient: sqlClient,
+	}
+}
+
+func (r *repo) Ping(ctx context.Context) error {
+	return r.sqlClient.PingContext(ctx)
+}
+
+func (r *repo) GetSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := r.sqlClient.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
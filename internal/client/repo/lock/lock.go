@@ -0,0 +1,52 @@
+// Package lock provides Postgres advisory locks, so that a job runs on only one instance at a time.
+package lock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Keys of the advisory locks taken by background jobs.
+const (
+	KeyReminder int64 = 1001
+)
+
+type Locker interface {
+	// TryLock takes the lock without waiting. When ok, release must be called to give it back.
+	TryLock(ctx context.Context, key int64) (release func(), ok bool, err error)
+}
+
+type locker struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Locker {
+	return &locker{
+		sqlClient: sqlClient,
+	}
+}
+
+// TryLock takes a session-level lock on a dedicated connection, if the instance dies
+// the connection is closed and Postgres releases the lock.
+func (l *locker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	conn, err := l.sqlClient.Connx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err = conn.GetContext(ctx, &ok, "SELECT pg_try_advisory_lock($1)", key); err != nil {
+		_ = conn.Close()
+		return nil, false, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+	if !ok {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+	release := func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		_ = conn.Close()
+	}
+	return release, true, nil
+}
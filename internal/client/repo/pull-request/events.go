@@ -0,0 +1,160 @@
+package pull_request
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/doverlof/avito_help/internal/actor"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// recordEvents appends events to the history in the caller's tx and queues them for webhooks and the code host sync.
+func (r *repo) recordEvents(ctx context.Context, tx *sqlx.Tx, events ...model.PullRequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	who := nullIfEmpty(actor.FromContext(ctx))
+	builder := sq.Insert("pr_events").Columns(
+		"pull_request_id",
+		"event_type",
+		"actor",
+		"reviewer_id",
+		"old_reviewer_id",
+		"from_status",
+		"to_status",
+		"verdict",
+		"reason",
+	).PlaceholderFormat(sq.Dollar)
+	for _, event := range events {
+		builder = builder.Values(
+			event.PullRequestID,
+			event.Type,
+			who,
+			nullIfEmpty(event.ReviewerID),
+			nullIfEmpty(event.OldReviewerID),
+			nullIfEmpty(string(event.FromStatus)),
+			nullIfEmpty(string(event.ToStatus)),
+			nullIfEmpty(string(event.Verdict)),
+			nullIfEmpty(event.Reason),
+		)
+	}
+	query, args, err := builder.Suffix("RETURNING event_id, event_type, pull_request_id").ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	enqueue := enqueueEvents
+	if r.syncCodeHost {
+		enqueue = enqueueEventsAndSync
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(enqueue, query), args...); err != nil {
+		return fmt.Errorf("failed to record pull request events: %w", err)
+	}
+	return nil
+}
+
+// enqueueWebhooks adds an outbox row per matching active subscription for the events in e.
+const enqueueWebhooks = `
+	INSERT INTO webhook_deliveries (subscription_id, event_id)
+	SELECT s.subscription_id, e.event_id
+	FROM e
+	JOIN webhook_subscriptions s
+	  ON s.is_active AND (cardinality(s.event_types) = 0 OR e.event_type = ANY(s.event_types))
+`
+
+// enqueueEvents wraps the pr_events insert and queues the events for the webhooks.
+const enqueueEvents = `WITH e AS (%s)` + enqueueWebhooks
+
+// enqueueEventsAndSync also queues the code host sync of pull requests whose reviewers changed.
+const enqueueEventsAndSync = `
+	WITH e AS (%s),
+	webhooks AS (` + enqueueWebhooks + `)
+	INSERT INTO code_host_syncs AS c (pull_request_id)
+	SELECT DISTINCT e.pull_request_id
+	FROM e
+	WHERE e.event_type IN ('ASSIGNED', 'REASSIGNED')
+	ON CONFLICT (pull_request_id) DO UPDATE SET
+		revision = c.revision + 1,
+		status = 'PENDING',
+		attempts = CASE WHEN c.status = 'PENDING' THEN c.attempts ELSE 0 END,
+		next_attempt_at = CASE WHEN c.status = 'PENDING' THEN c.next_attempt_at ELSE now() END,
+		updated_at = now()
+`
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type pullRequestEvent struct {
+	ID            int64          `db:"event_id"`
+	PullRequestID string         `db:"pull_request_id"`
+	Type          string         `db:"event_type"`
+	Actor         sql.NullString `db:"actor"`
+	ReviewerID    sql.NullString `db:"reviewer_id"`
+	OldReviewerID sql.NullString `db:"old_reviewer_id"`
+	FromStatus    sql.NullString `db:"from_status"`
+	ToStatus      sql.NullString `db:"to_status"`
+	Verdict       sql.NullString `db:"verdict"`
+	Reason        sql.NullString `db:"reason"`
+	CreatedAt     sql.NullTime   `db:"created_at"`
+}
+
+func convertEvent(row pullRequestEvent) model.PullRequestEvent {
+	return model.PullRequestEvent{
+		ID:            row.ID,
+		PullRequestID: row.PullRequestID,
+		Type:          model.PullRequestEventType(row.Type),
+		Actor:         row.Actor.String,
+		ReviewerID:    row.ReviewerID.String,
+		OldReviewerID: row.OldReviewerID.String,
+		FromStatus:    model.PullRequestStatus(row.FromStatus.String),
+		ToStatus:      model.PullRequestStatus(row.ToStatus.String),
+		Verdict:       model.ReviewVerdict(row.Verdict.String),
+		Reason:        row.Reason.String,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+}
+
+// GetHistory returns the pull request events in the order they happened.
+func (r *repo) GetHistory(ctx context.Context, pullRequestID string) ([]model.PullRequestEvent, error) {
+	query, args, err := sq.Select(
+		"event_id",
+		"pull_request_id",
+		"event_type",
+		"actor",
+		"reviewer_id",
+		"old_reviewer_id",
+		"from_status",
+		"to_status",
+		"verdict",
+		"reason",
+		"created_at",
+	).From("pr_events").
+		Where(sq.Eq{"pull_request_id": pullRequestID}).
+		OrderBy("event_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []pullRequestEvent
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get pull request history: %w", err)
+	}
+	if len(rows) == 0 {
+		// pull requests created before the history existed have no events
+		if _, err = r.GetByID(ctx, pullRequestID); err != nil {
+			return nil, err
+		}
+	}
+	res := make([]model.PullRequestEvent, len(rows))
+	for i, row := range rows {
+		res[i] = convertEvent(row)
+	}
+	return res, nil
+}
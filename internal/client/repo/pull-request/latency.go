@@ -0,0 +1,175 @@
+package pull_request
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/lib/pq"
+)
+
+// mergedInWindow selects pull requests merged within [$2, $3), $1 is the MERGED status and $4 the team or NULL.
+const mergedInWindow = `
+	WITH merged AS (
+		SELECT
+			p.pull_request_id,
+			p.merged_at,
+			COALESCE(u.team_name, '') AS team_name,
+			date_trunc('week', p.merged_at) AS week,
+			EXTRACT(EPOCH FROM p.merged_at - p.created_at)::float8 AS seconds
+		FROM pull_requests p
+		JOIN users u ON u.user_id = p.author_id
+		WHERE p.status = $1 AND p.merged_at >= $2 AND p.merged_at < $3 AND ($4::text IS NULL OR u.team_name = $4)
+	)`
+
+// latencyPercentiles are the percentiles percentile_cont computes, in the order of model.Percentiles.
+const latencyPercentiles = "ARRAY[0.5, 0.9, 0.99]"
+
+// mergeLatencyQuery computes time-to-merge percentiles of the pull requests overall, per author's team and per week.
+const mergeLatencyQuery = mergedInWindow + `
+	SELECT
+		CASE WHEN GROUPING(team_name) = 0 THEN 'team' WHEN GROUPING(week) = 0 THEN 'week' ELSE 'all' END AS grouped_by,
+		COALESCE(team_name, '') AS team_name,
+		week,
+		'' AS reviewer_id,
+		'' AS reviewer_team,
+		COUNT(*) AS merged_prs,
+		0 AS assignments,
+		percentile_cont(` + latencyPercentiles + `) WITHIN GROUP (ORDER BY seconds) AS time_to_merge,
+		NULL::float8[] AS assignment_to_merge
+	FROM merged
+	GROUP BY GROUPING SETS ((), (team_name), (week))
+	ORDER BY grouped_by, team_name, week
+`
+
+// assignmentLatencyQuery computes assignment-to-merge percentiles of the reviewers of the same pull requests.
+const assignmentLatencyQuery = mergedInWindow + `,
+	assignments AS (
+		SELECT
+			m.pull_request_id,
+			m.team_name,
+			m.week,
+			r.reviewer_id,
+			COALESCE(ru.team_name, '') AS reviewer_team,
+			m.seconds AS merge_seconds,
+			EXTRACT(EPOCH FROM m.merged_at - r.assigned_at)::float8 AS seconds
+		FROM merged m
+		JOIN pr_reviewers r ON r.pull_request_id = m.pull_request_id
+		JOIN users ru ON ru.user_id = r.reviewer_id
+		WHERE r.assigned_at IS NOT NULL
+	)
+	SELECT
+		CASE
+			WHEN GROUPING(reviewer_id) = 0 THEN 'reviewer'
+			WHEN GROUPING(team_name) = 0 THEN 'team'
+			WHEN GROUPING(week) = 0 THEN 'week'
+			ELSE 'all'
+		END AS grouped_by,
+		COALESCE(team_name, '') AS team_name,
+		week,
+		COALESCE(reviewer_id, '') AS reviewer_id,
+		COALESCE(reviewer_team, '') AS reviewer_team,
+		COUNT(DISTINCT pull_request_id) AS merged_prs,
+		COUNT(*) AS assignments,
+		percentile_cont(` + latencyPercentiles + `) WITHIN GROUP (ORDER BY merge_seconds) AS time_to_merge,
+		percentile_cont(` + latencyPercentiles + `) WITHIN GROUP (ORDER BY seconds) AS assignment_to_merge
+	FROM assignments
+	GROUP BY GROUPING SETS ((), (team_name), (week), (reviewer_id, reviewer_team))
+	ORDER BY grouped_by, team_name, week, reviewer_id
+`
+
+type latencyDB struct {
+	GroupedBy         string          `db:"grouped_by"`
+	TeamName          string          `db:"team_name"`
+	Week              sql.NullTime    `db:"week"`
+	ReviewerID        string          `db:"reviewer_id"`
+	ReviewerTeam      string          `db:"reviewer_team"`
+	MergedPrs         int             `db:"merged_prs"`
+	Assignments       int             `db:"assignments"`
+	TimeToMerge       pq.Float64Array `db:"time_to_merge"`
+	AssignmentToMerge pq.Float64Array `db:"assignment_to_merge"`
+}
+
+// GetLatency reports how long pull requests merged within [from, to) waited. The team filter matches the author's team.
+func (r *repo) GetLatency(ctx context.Context, from, to time.Time, teamName string) (model.LatencyReport, error) {
+	args := []interface{}{model.StatusMerge, from, to, nullIfEmpty(teamName)}
+	var merges, assignments []latencyDB
+	if err := r.sqlClient.SelectContext(ctx, &merges, mergeLatencyQuery, args...); err != nil {
+		return model.LatencyReport{}, fmt.Errorf("failed to get merge latency: %w", err)
+	}
+	if err := r.sqlClient.SelectContext(ctx, &assignments, assignmentLatencyQuery, args...); err != nil {
+		return model.LatencyReport{}, fmt.Errorf("failed to get assignment latency: %w", err)
+	}
+
+	res := model.LatencyReport{
+		From:      from,
+		To:        to,
+		Teams:     make([]model.TeamLatency, 0),
+		Reviewers: make([]model.ReviewerLatency, 0),
+		Weekly:    make([]model.WeeklyLatency, 0),
+	}
+	teams := make(map[string]*model.Latency)
+	weeks := make(map[int64]*model.Latency)
+	for _, row := range merges {
+		latency := model.Latency{MergedPrs: row.MergedPrs, TimeToMerge: convertPercentiles(row.TimeToMerge)}
+		switch row.GroupedBy {
+		case "team":
+			res.Teams = append(res.Teams, model.TeamLatency{TeamName: row.TeamName, Latency: latency})
+		case "week":
+			res.Weekly = append(res.Weekly, model.WeeklyLatency{WeekStart: row.Week.Time, Latency: latency})
+		default:
+			res.Overall = latency
+		}
+	}
+	for i := range res.Teams {
+		teams[res.Teams[i].TeamName] = &res.Teams[i].Latency
+	}
+	for i := range res.Weekly {
+		weeks[res.Weekly[i].WeekStart.Unix()] = &res.Weekly[i].Latency
+	}
+	for _, row := range assignments {
+		var latency *model.Latency
+		switch row.GroupedBy {
+		case "reviewer":
+			res.Reviewers = append(res.Reviewers, model.ReviewerLatency{
+				ReviewerID: row.ReviewerID,
+				TeamName:   row.ReviewerTeam,
+				Latency: model.Latency{
+					MergedPrs:         row.MergedPrs,
+					Assignments:       row.Assignments,
+					TimeToMerge:       convertPercentiles(row.TimeToMerge),
+					AssignmentToMerge: convertPercentiles(row.AssignmentToMerge),
+				},
+			})
+			continue
+		case "team":
+			latency = teams[row.TeamName]
+		case "week":
+			latency = weeks[row.Week.Time.Unix()]
+		default:
+			latency = &res.Overall
+		}
+		if latency != nil {
+			latency.Assignments = row.Assignments
+			latency.AssignmentToMerge = convertPercentiles(row.AssignmentToMerge)
+		}
+	}
+	return res, nil
+}
+
+// convertPercentiles converts percentile_cont seconds, NULL when the group was empty.
+func convertPercentiles(seconds pq.Float64Array) *model.Percentiles {
+	if len(seconds) != 3 {
+		return nil
+	}
+	toDuration := func(s float64) time.Duration {
+		return time.Duration(s * float64(time.Second)).Round(time.Second)
+	}
+	return &model.Percentiles{
+		P50: toDuration(seconds[0]),
+		P90: toDuration(seconds[1]),
+		P99: toDuration(seconds[2]),
+	}
+}
@@ -0,0 +1,134 @@
+package pull_request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrPlanOutdated is returned by writes whose reviewers were changed concurrently since they were picked.
+var ErrPlanOutdated = errors.New("reviewers changed since they were picked")
+
+// lockActiveReviewers locks the users about to be assigned until the end of tx.
+func lockActiveReviewers(ctx context.Context, tx *sqlx.Tx, reviewerIDs []string) error {
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+	ids := slices.Clone(reviewerIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	query, args, err := sq.Select("user_id", "is_active").From("users").
+		Where(sq.Eq{"user_id": ids}).
+		OrderBy("user_id").Suffix("FOR SHARE").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	var users []struct {
+		ID       string `db:"user_id"`
+		IsActive bool   `db:"is_active"`
+	}
+	if err = tx.SelectContext(ctx, &users, query, args...); err != nil {
+		return fmt.Errorf("failed to lock reviewers: %w", err)
+	}
+	if len(users) != len(ids) {
+		return ErrDontHaveReviewer
+	}
+	for _, user := range users {
+		if !user.IsActive {
+			return fmt.Errorf("%w: %s was deactivated", ErrPlanOutdated, user.ID)
+		}
+	}
+	return nil
+}
+
+type assignment struct {
+	PullRequestID string `db:"pull_request_id"`
+	ReviewerID    string `db:"reviewer_id"`
+}
+
+// lockOpenByReviewers locks the OPEN pull requests reviewed by any of userIDs and returns their reviewers.
+// The reviewers are read after the locks are held, so they include the changes the locks waited for.
+func lockOpenByReviewers(ctx context.Context, tx *sqlx.Tx, userIDs []string) (map[string][]string, error) {
+	reviewed := sq.Select("pull_request_id").From("pr_reviewers").Where(sq.Eq{"reviewer_id": userIDs})
+	reviewedSql, reviewedArgs, err := reviewed.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	query, args, err := sq.Select("pull_request_id").From("pull_requests").
+		Where(sq.Eq{"status": model.StatusOpen}).
+		Where("pull_request_id IN ("+reviewedSql+")", reviewedArgs...).
+		OrderBy("pull_request_id").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var ids []string
+	if err = tx.SelectContext(ctx, &ids, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to lock open pull requests: %w", err)
+	}
+	res := make(map[string][]string, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	query, args, err = sq.Select("pull_request_id", "reviewer_id").From("pr_reviewers").
+		Where(sq.Eq{"pull_request_id": ids}).
+		OrderBy("pull_request_id", "reviewer_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []assignment
+	if err = tx.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	for _, id := range ids {
+		res[id] = []string{}
+	}
+	for _, row := range rows {
+		res[row.PullRequestID] = append(res[row.PullRequestID], row.ReviewerID)
+	}
+	return res, nil
+}
+
+// checkPlan returns ErrPlanOutdated unless planned lists the same pull requests with the same reviewers as locked.
+func checkPlan(planned []model.PullRequest, locked map[string][]string) error {
+	if len(planned) != len(locked) {
+		return fmt.Errorf("%w: %d open pull requests, %d planned", ErrPlanOutdated, len(locked), len(planned))
+	}
+	for _, pr := range planned {
+		reviewers, current := slices.Clone(pr.ReviewerIDs), slices.Clone(locked[pr.PullRequestID])
+		slices.Sort(reviewers)
+		slices.Sort(current)
+		if current == nil || !slices.Equal(reviewers, current) {
+			return fmt.Errorf("%w: reviewers of %s changed", ErrPlanOutdated, pr.PullRequestID)
+		}
+	}
+	return nil
+}
+
+// outdatedOnDeadlock turns a deadlock with a concurrent assignment into ErrPlanOutdated.
+func outdatedOnDeadlock(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "40P01" {
+		return fmt.Errorf("%w: %s", ErrPlanOutdated, pgErr.Message)
+	}
+	return err
+}
+
+func reviewerIDs(reviewers []model.Reviewer) []string {
+	ids := make([]string, len(reviewers))
+	for i, reviewer := range reviewers {
+		ids[i] = reviewer.ID
+	}
+	return ids
+}
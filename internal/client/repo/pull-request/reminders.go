@@ -0,0 +1,127 @@
+package pull_request
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type staleReviewDB struct {
+	PullRequestID string       `db:"pull_request_id"`
+	ReviewerID    string       `db:"reviewer_id"`
+	AssignedAt    time.Time    `db:"assigned_at"`
+	RemindedAt    sql.NullTime `db:"reminded_at"`
+	EscalatedAt   sql.NullTime `db:"escalated_at"`
+}
+
+// GetStale returns unreviewed assignments due a reminder or an escalation, the oldest first. A zero time turns a step off.
+func (r *repo) GetStale(ctx context.Context, remindBefore, escalateBefore time.Time, limit int) ([]model.StaleReview, error) {
+	due := sq.Or{}
+	if !remindBefore.IsZero() {
+		due = append(due, sq.And{sq.Eq{"r.reminded_at": nil}, sq.Lt{"r.assigned_at": remindBefore}})
+	}
+	if !escalateBefore.IsZero() {
+		due = append(due, sq.Lt{"r.assigned_at": escalateBefore})
+	}
+	if len(due) == 0 {
+		return []model.StaleReview{}, nil
+	}
+	query, args, err := sq.Select(
+		"r.pull_request_id",
+		"r.reviewer_id",
+		"r.assigned_at",
+		"r.reminded_at",
+		"r.escalated_at",
+	).From("pr_reviewers r").
+		Join("pull_requests p ON p.pull_request_id = r.pull_request_id").
+		Where(sq.Eq{"p.status": model.StatusOpen}).
+		Where(sq.Eq{"r.escalated_at": nil}).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM pr_reviews v
+			WHERE v.pull_request_id = r.pull_request_id AND v.reviewer_id = r.reviewer_id
+		)`).
+		Where(due).
+		OrderBy("r.assigned_at", "r.pull_request_id", "r.reviewer_id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var rows []staleReviewDB
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get stale reviews: %w", err)
+	}
+	res := make([]model.StaleReview, len(rows))
+	for i, row := range rows {
+		res[i] = model.StaleReview{
+			PullRequestID: row.PullRequestID,
+			ReviewerID:    row.ReviewerID,
+			AssignedAt:    row.AssignedAt,
+			RemindedAt:    row.RemindedAt.Time,
+			EscalatedAt:   row.EscalatedAt.Time,
+		}
+	}
+	return res, nil
+}
+
+// MarkReminded records that the reviewer was reminded and emits a REMINDED event.
+func (r *repo) MarkReminded(ctx context.Context, pullRequestID, reviewerID string) error {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	affected, err := markStale(ctx, tx, "reminded_at", pullRequestID, reviewerID)
+	if err != nil || !affected {
+		return err
+	}
+	err = r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventReminded,
+		ReviewerID:    reviewerID,
+	})
+	return err
+}
+
+// MarkEscalated records an escalation nobody could take over, so the worker doesn't pick it again.
+func (r *repo) MarkEscalated(ctx context.Context, pullRequestID, reviewerID string) error {
+	_, err := markStale(ctx, r.sqlClient, "escalated_at", pullRequestID, reviewerID)
+	return err
+}
+
+// markStale sets column of the assignment unless it is already set and reports whether it did.
+func markStale(ctx context.Context, exec Execer, column, pullRequestID, reviewerID string) (bool, error) {
+	query, args, err := sq.Update("pr_reviewers").
+		Set(column, time.Now()).
+		Where(sq.Eq{"pull_request_id": pullRequestID}, sq.Eq{"reviewer_id": reviewerID}, sq.Eq{column: nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return false, repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark %s: %w", column, err)
+	}
+	v, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return v > 0, nil
+}
+
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
@@ -2,15 +2,18 @@ package pull_request
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/client/repo/rotation"
 	"github.com/doverlof/avito_help/internal/model"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var (
@@ -18,15 +21,36 @@ var (
 	ErrPRNotFound       = errors.New("pull request not found")
 	ErrDontHaveReviewer = errors.New("dont have reviewers")
 	ErrNoRowsAffected   = errors.New("no rows affected")
+	ErrPRMerged         = errors.New("pull request already merged")
+	ErrPRClosed         = errors.New("pull request is closed")
+	ErrNotAssigned      = errors.New("reviewer is not assigned to this PR")
+	ErrNotApproved      = errors.New("not enough approvals")
 )
 
 type Repo interface {
-	Create(ctx context.Context, pullRequest model.CreatePullRequest, reviewers []model.User) error
-	Merge(ctx context.Context, pullRequestID string) (model.PullRequest, error)
-	GetByReviewer(ctx context.Context, userID string) ([]model.PullRequest, error)
+	// Create, Reopen, ChangeReviewer and DeactivateReviewers apply moves with rotation.Advance.
+	Create(ctx context.Context, pullRequest model.CreatePullRequest, reviewers []model.Reviewer, moves []model.RotationMove) (model.PullRequest, error)
+	Merge(ctx context.Context, pullRequestID string, requiredApprovals int) (model.PullRequest, error)
+	AddReview(ctx context.Context, review model.Review) (model.PullRequest, error)
+	Close(ctx context.Context, pullRequestID string) (model.PullRequest, error)
+	Reopen(ctx context.Context, pullRequestID string, kept []model.Reviewer, reassignments []model.Reassignment, moves []model.RotationMove) (model.PullRequest, error)
+	GetByReviewer(ctx context.Context, userID string, includeClosed bool) ([]model.PullRequest, error)
 	GetByID(ctx context.Context, pullRequestID string) (model.PullRequest, error)
-	ChangeReviewer(ctx context.Context, pullRequestID, oldReviewerID, reviewerID string) (model.PullRequest, error)
-	GetUserStatistics(ctx context.Context) ([]model.UserStatistics, error)
+	ChangeReviewer(ctx context.Context, pullRequestID, oldReviewerID string, reviewer model.Reviewer, reason string, moves []model.RotationMove) (model.PullRequest, error)
+	GetHistory(ctx context.Context, pullRequestID string) ([]model.PullRequestEvent, error)
+	GetOpenByReviewers(ctx context.Context, userIDs []string) ([]model.PullRequest, error)
+	DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, planned []model.PullRequest, reassignments []model.Reassignment, moves []model.RotationMove) error
+	GetUserStatistics(ctx context.Context, filter model.StatsFilter) ([]model.UserStatistics, error)
+	RecomputeUserStats(ctx context.Context) (int, error)
+	GetTeamStatistics(ctx context.Context, filter model.StatsFilter) ([]model.TeamStatistics, error)
+	GetLatency(ctx context.Context, from, to time.Time, teamName string) (model.LatencyReport, error)
+	GetOverdue(ctx context.Context, filter model.OverdueFilter) ([]model.OverdueReview, error)
+	GetSLACompliance(ctx context.Context, from, to time.Time, teamName string) ([]model.TeamSLACompliance, error)
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+	CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+	GetStale(ctx context.Context, remindBefore, escalateBefore time.Time, limit int) ([]model.StaleReview, error)
+	MarkReminded(ctx context.Context, pullRequestID, reviewerID string) error
+	MarkEscalated(ctx context.Context, pullRequestID, reviewerID string) error
 }
 
 type Selector interface {
@@ -35,18 +59,21 @@ type Selector interface {
 
 type repo struct {
 	sqlClient *sqlx.DB
+	// syncCodeHost queues assignment changes for the code host sync, it is off when the syncer doesn't run.
+	syncCodeHost bool
 }
 
-func New(sqlClient *sqlx.DB) Repo {
+func New(sqlClient *sqlx.DB, syncCodeHost bool) Repo {
 	return &repo{
-		sqlClient: sqlClient,
+		sqlClient:    sqlClient,
+		syncCodeHost: syncCodeHost,
 	}
 }
 
-func (r *repo) Create(ctx context.Context, pullRequest model.CreatePullRequest, reviewers []model.User) error {
+func (r *repo) Create(ctx context.Context, pullRequest model.CreatePullRequest, reviewers []model.Reviewer, moves []model.RotationMove) (model.PullRequest, error) {
 	tx, err := r.sqlClient.Beginx()
 	if err != nil {
-		return err
+		return model.PullRequest{}, err
 	}
 	defer func() {
 		if err != nil {
@@ -60,60 +87,202 @@ func (r *repo) Create(ctx context.Context, pullRequest model.CreatePullRequest,
 		"pull_request_id",
 		"pull_request_name",
 		"author_id",
+		"labels",
 	).Values(
 		pullRequest.PullRequestID,
 		pullRequest.PullRequestName,
 		pullRequest.AuthorID,
+		textArray(pullRequest.Labels),
 	).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
-		return repo2.ErrToCreateToCreateSql(err)
+		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
 	}
 
 	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		if pqErr, ok := err.(*pgconn.PgError); ok && pqErr.Code == "23505" {
 			// unique violation
-			return ErrPRExists
+			return model.PullRequest{}, ErrPRExists
 		}
-		return err
+		return model.PullRequest{}, err
+	}
+	if err = lockActiveReviewers(ctx, tx, reviewerIDs(reviewers)); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = rotation.Advance(ctx, tx, moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	events := []model.PullRequestEvent{{
+		PullRequestID: pullRequest.PullRequestID,
+		Type:          model.EventCreated,
+		ToStatus:      model.StatusOpen,
+	}}
+	delta := statsDelta{}
+	delta.authored(pullRequest.AuthorID, model.StatusOpen, 1)
+	for _, reviewer := range reviewers {
+		events = append(events, assignedEvent(pullRequest.PullRequestID, reviewer))
+		delta.review(reviewer.ID, model.StatusOpen, 1)
+	}
+	if len(reviewers) == 0 {
+		if err = delta.apply(ctx, tx); err != nil {
+			return model.PullRequest{}, err
+		}
+		if err = r.recordEvents(ctx, tx, events...); err != nil {
+			return model.PullRequest{}, err
+		}
+		var stored model.PullRequest
+		stored, err = selectByID(ctx, tx, pullRequest.PullRequestID)
+		return stored, err
 	}
 
-	builder := sq.Insert("pr_reviewers").Columns("pull_request_id", "reviewer_id").PlaceholderFormat(sq.Dollar)
+	builder := sq.Insert("pr_reviewers").
+		Columns("pull_request_id", "reviewer_id", "is_fallback", "is_code_owner", "matched_tags", "due_at").
+		PlaceholderFormat(sq.Dollar)
 	for _, reviewer := range reviewers {
-		builder = builder.Values(pullRequest.PullRequestID, reviewer.ID)
+		builder = builder.Values(pullRequest.PullRequestID, reviewer.ID, reviewer.IsFallback, reviewer.IsCodeOwner,
+			textArray(reviewer.MatchedTags), nullTime(reviewer.DueAt))
 	}
 
 	queryRev, argsRev, err := builder.ToSql()
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
-			return ErrDontHaveReviewer
-		}
-		return err
+		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
 	}
 
 	_, err = tx.ExecContext(ctx, queryRev, argsRev...)
 	if err != nil {
-		return err
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return model.PullRequest{}, ErrDontHaveReviewer
+		}
+		return model.PullRequest{}, err
 	}
-	return nil
+	if err = delta.apply(ctx, tx); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = r.recordEvents(ctx, tx, events...); err != nil {
+		return model.PullRequest{}, err
+	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, pullRequest.PullRequestID)
+	return stored, err
+}
+
+// textArray converts a possibly nil slice for a NOT NULL text[] column.
+func textArray(values []string) interface{} {
+	if values == nil {
+		values = []string{}
+	}
+	return pq.Array(values)
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func assignedEvent(pullRequestID string, reviewer model.Reviewer) model.PullRequestEvent {
+	event := model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventAssigned,
+		ReviewerID:    reviewer.ID,
+	}
+	switch {
+	case reviewer.IsCodeOwner:
+		event.Reason = model.ReasonCodeOwner
+	case reviewer.IsFallback:
+		event.Reason = model.ReasonFallbackTeam
+	}
+	return event
 }
 
 type pullRequestByReviewer struct {
-	PullRequestID   string    `db:"pull_request_id"`
-	PullRequestName string    `db:"pull_request_name"`
-	AuthorID        string    `db:"author_id"`
-	Status          string    `db:"status"`
-	MergedAt        time.Time `db:"merged_at"`
-	ReviewerID      string    `db:"reviewer_id"`
+	PullRequestID   string         `db:"pull_request_id"`
+	PullRequestName string         `db:"pull_request_name"`
+	AuthorID        string         `db:"author_id"`
+	Status          string         `db:"status"`
+	MergedAt        sql.NullTime   `db:"merged_at"`
+	ClosedAt        sql.NullTime   `db:"closed_at"`
+	CreatedAt       sql.NullTime   `db:"created_at"`
+	NeedsReviewer   bool           `db:"needs_reviewer"`
+	ReviewerID      string         `db:"reviewer_id"`
+	ReviewerTeam    string         `db:"reviewer_team"`
+	ReviewerActive  bool           `db:"reviewer_active"`
+	AssignedAt      sql.NullTime   `db:"assigned_at"`
+	IsFallback      bool           `db:"is_fallback"`
+	IsCodeOwner     bool           `db:"is_code_owner"`
+	MatchedTags     pq.StringArray `db:"matched_tags"`
+	DueAt           sql.NullTime   `db:"due_at"`
+	IsOverdue       bool           `db:"is_overdue"`
+	Labels          pq.StringArray `db:"labels"`
+	Verdict         string         `db:"verdict"`
+	VerdictAt       sql.NullTime   `db:"verdict_at"`
 }
 
-func (r *repo) Merge(ctx context.Context, pullRequestID string) (model.PullRequest, error) {
+// Merge marks the pull request as MERGED once at least requiredApprovals assigned reviewers approved it.
+func (r *repo) Merge(ctx context.Context, pullRequestID string, requiredApprovals int) (model.PullRequest, error) {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	status, err := lockStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	switch status {
+	case model.StatusMerge:
+		var stored model.PullRequest
+		stored, err = selectByID(ctx, tx, pullRequestID)
+		return stored, err
+	case model.StatusClosed:
+		err = ErrPRClosed
+		return model.PullRequest{}, err
+	}
+	if requiredApprovals > 0 {
+		var approvals int
+		if err = tx.GetContext(ctx, &approvals, countApprovals, pullRequestID); err != nil {
+			return model.PullRequest{}, fmt.Errorf("failed to count approvals: %w", err)
+		}
+		if approvals < requiredApprovals {
+			err = ErrNotApproved
+			return model.PullRequest{}, err
+		}
+	}
+
 	query, args, err := sq.Update("pull_requests").Set("status", model.StatusMerge).
 		Set("merged_at", time.Now()).
 		Where(sq.Eq{"pull_request_id": pullRequestID}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
 	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = moveStatus(ctx, tx, pullRequestID, status, model.StatusMerge); err != nil {
+		return model.PullRequest{}, err
+	}
+	err = r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventMerged,
+		FromStatus:    status,
+		ToStatus:      model.StatusMerge,
+	})
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, pullRequestID)
+	return stored, err
+}
+
+// Close marks an OPEN pull request as CLOSED. Closing a CLOSED pull request returns it unchanged.
+func (r *repo) Close(ctx context.Context, pullRequestID string) (model.PullRequest, error) {
 	tx, err := r.sqlClient.Beginx()
 	if err != nil {
 		return model.PullRequest{}, err
@@ -125,38 +294,306 @@ func (r *repo) Merge(ctx context.Context, pullRequestID string) (model.PullReque
 		}
 		_ = tx.Commit()
 	}()
-	res, err := tx.ExecContext(ctx, query, args...)
+
+	status, err := lockStatus(ctx, tx, pullRequestID)
 	if err != nil {
 		return model.PullRequest{}, err
 	}
+	switch status {
+	case model.StatusClosed:
+		var stored model.PullRequest
+		stored, err = selectByID(ctx, tx, pullRequestID)
+		return stored, err
+	case model.StatusMerge:
+		err = ErrPRMerged
+		return model.PullRequest{}, err
+	}
+
+	query, args, err := sq.Update("pull_requests").Set("status", model.StatusClosed).
+		Set("closed_at", time.Now()).
+		Where(sq.Eq{"pull_request_id": pullRequestID}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = moveStatus(ctx, tx, pullRequestID, status, model.StatusClosed); err != nil {
+		return model.PullRequest{}, err
+	}
+	err = r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventClosed,
+		FromStatus:    status,
+		ToStatus:      model.StatusClosed,
+	})
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, pullRequestID)
+	return stored, err
+}
+
+// Reopen moves a CLOSED pull request back to OPEN and replaces reviewers according to reassignments.
+func (r *repo) Reopen(ctx context.Context, pullRequestID string, kept []model.Reviewer, reassignments []model.Reassignment, moves []model.RotationMove) (model.PullRequest, error) {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	status, err := lockStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	switch status {
+	case model.StatusOpen:
+		var stored model.PullRequest
+		stored, err = selectByID(ctx, tx, pullRequestID)
+		return stored, err
+	case model.StatusMerge:
+		err = ErrPRMerged
+		return model.PullRequest{}, err
+	}
+
+	query, args, err := sq.Update("pull_requests").Set("status", model.StatusOpen).
+		Set("closed_at", nil).
+		Where(sq.Eq{"pull_request_id": pullRequestID}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = moveStatus(ctx, tx, pullRequestID, status, model.StatusOpen); err != nil {
+		return model.PullRequest{}, err
+	}
+	err = r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventReopened,
+		FromStatus:    status,
+		ToStatus:      model.StatusOpen,
+	})
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = rotation.Advance(ctx, tx, moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = reassignKept(ctx, tx, pullRequestID, kept); err != nil {
+		return model.PullRequest{}, err
+	}
+	for _, reassignment := range reassignments {
+		if reassignment.NewReviewerID == "" {
+			err = r.recordEvents(ctx, tx, needsReviewerEvent(reassignment, model.ReasonReviewerInactive))
+			if err != nil {
+				return model.PullRequest{}, err
+			}
+			continue
+		}
+		err = r.changeReviewer(ctx, tx, pullRequestID, reassignment.OldReviewerID, model.Reviewer{
+			ID:          reassignment.NewReviewerID,
+			IsFallback:  reassignment.IsFallback,
+			MatchedTags: reassignment.MatchedTags,
+			DueAt:       reassignment.DueAt,
+		}, model.ReasonReviewerInactive)
+		if err != nil {
+			return model.PullRequest{}, fmt.Errorf("failed to replace %s: %w", reassignment.OldReviewerID, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, refreshNeedsReviewer, pullRequestID); err != nil {
+		return model.PullRequest{}, err
+	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, pullRequestID)
+	return stored, err
+}
+
+// reassignKept restarts the assignments of reviewers who stay on a reopened pull request.
+func reassignKept(ctx context.Context, tx *sqlx.Tx, pullRequestID string, kept []model.Reviewer) error {
+	now := time.Now()
+	for _, reviewer := range kept {
+		query, args, err := sq.Update("pr_reviewers").
+			Set("assigned_at", now).
+			Set("due_at", nullTime(reviewer.DueAt)).
+			Set("reminded_at", nil).
+			Set("escalated_at", nil).
+			Where(sq.Eq{"pull_request_id": pullRequestID, "reviewer_id": reviewer.ID}).
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return repo2.ErrToCreateToCreateSql(err)
+		}
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to reassign %s: %w", reviewer.ID, err)
+		}
+	}
+	return nil
+}
+
+// countApprovals counts assigned reviewers whose latest verdict is APPROVED.
+const countApprovals = `
+	SELECT COUNT(*)
+	FROM pr_reviewers r
+	JOIN LATERAL (
+		SELECT v.verdict
+		FROM pr_reviews v
+		WHERE v.pull_request_id = r.pull_request_id AND v.reviewer_id = r.reviewer_id
+		ORDER BY v.created_at DESC, v.review_id DESC
+		LIMIT 1
+	) v ON true
+	WHERE r.pull_request_id = $1 AND v.verdict = 'APPROVED'
+`
+
+// AddReview stores a verdict of an assigned reviewer on an OPEN pull request.
+func (r *repo) AddReview(ctx context.Context, review model.Review) (model.PullRequest, error) {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	status, err := lockStatus(ctx, tx, review.PullRequestID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	switch status {
+	case model.StatusMerge:
+		err = ErrPRMerged
+		return model.PullRequest{}, err
+	case model.StatusClosed:
+		err = ErrPRClosed
+		return model.PullRequest{}, err
+	}
+
+	var comment *string
+	if review.Comment != "" {
+		comment = &review.Comment
+	}
+	assigned := sq.Select("1").From("pr_reviewers").
+		Where(sq.Eq{"pull_request_id": review.PullRequestID, "reviewer_id": review.ReviewerID})
+	query, args, err := sq.Insert("pr_reviews").
+		Columns("pull_request_id", "reviewer_id", "verdict", "comment").
+		Select(sq.Select().
+			Column("?::text", review.PullRequestID).
+			Column("?::text", review.ReviewerID).
+			Column("?::text", string(review.Verdict)).
+			Column("?::text", comment).
+			Where(sq.Expr("EXISTS (?)", assigned))).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return model.PullRequest{}, fmt.Errorf("failed to add review: %w", err)
+	}
 	v, err := res.RowsAffected()
 	if err != nil {
 		return model.PullRequest{}, err
 	}
 	if v == 0 {
-		return model.PullRequest{}, ErrPRNotFound
+		err = ErrNotAssigned
+		return model.PullRequest{}, err
+	}
+	err = r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: review.PullRequestID,
+		Type:          model.EventReviewed,
+		ReviewerID:    review.ReviewerID,
+		Verdict:       review.Verdict,
+	})
+	if err != nil {
+		return model.PullRequest{}, err
 	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, review.PullRequestID)
+	return stored, err
+}
 
-	return selectByID(ctx, tx, pullRequestID)
+// lockStatus reads the pull request status and locks the row until the end of the transaction.
+func lockStatus(ctx context.Context, tx *sqlx.Tx, pullRequestID string) (model.PullRequestStatus, error) {
+	query, args, err := sq.Select("status").From("pull_requests").
+		Where(sq.Eq{"pull_request_id": pullRequestID}).Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return "", repo2.ErrToCreateToCreateSql(err)
+	}
+	var status model.PullRequestStatus
+	err = tx.GetContext(ctx, &status, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPRNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	return status, nil
 }
 
 func convertPullRequest(rows []pullRequestByReviewer) model.PullRequest {
-	reviewerIDs := make([]string, len(rows))
-	for i, row := range rows {
-		reviewerIDs[i] = row.ReviewerID
+	reviewerIDs := make([]string, 0, len(rows))
+	reviewers := make([]model.Reviewer, 0, len(rows))
+	for _, row := range rows {
+		if row.ReviewerID != "" {
+			reviewerIDs = append(reviewerIDs, row.ReviewerID)
+			reviewers = append(reviewers, model.Reviewer{
+				ID:          row.ReviewerID,
+				TeamName:    row.ReviewerTeam,
+				AssignedAt:  row.AssignedAt.Time,
+				IsActive:    row.ReviewerActive,
+				Verdict:     model.ReviewVerdict(row.Verdict),
+				VerdictAt:   row.VerdictAt.Time,
+				IsFallback:  row.IsFallback,
+				IsCodeOwner: row.IsCodeOwner,
+				MatchedTags: row.MatchedTags,
+				DueAt:       row.DueAt.Time,
+				IsOverdue:   row.IsOverdue,
+			})
+		}
 	}
 	return model.PullRequest{
 		AuthorID:        rows[0].AuthorID,
 		PullRequestID:   rows[0].PullRequestID,
 		PullRequestName: rows[0].PullRequestName,
 		Status:          model.PullRequestStatus(rows[0].Status),
-		MergedAt:        rows[0].MergedAt,
+		CreatedAt:       rows[0].CreatedAt.Time,
+		MergedAt:        rows[0].MergedAt.Time,
+		ClosedAt:        rows[0].ClosedAt.Time,
+		NeedsReviewer:   rows[0].NeedsReviewer,
+		Labels:          rows[0].Labels,
 		ReviewerIDs:     reviewerIDs,
+		Reviewers:       reviewers,
+	}
+}
+
+// groupPullRequests converts rows ordered by pull_request_id into pull requests.
+func groupPullRequests(rows []pullRequestByReviewer) []model.PullRequest {
+	res := make([]model.PullRequest, 0)
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && rows[end].PullRequestID == rows[start].PullRequestID {
+			end++
+		}
+		res = append(res, convertPullRequest(rows[start:end]))
+		start = end
 	}
+	return res
 }
 
-func (r *repo) GetByReviewer(ctx context.Context, userID string) ([]model.PullRequest, error) {
-	query, args, err := sq.Select(
+// GetByReviewer returns pull requests the user reviews. CLOSED pull requests are skipped unless includeClosed is set.
+func (r *repo) GetByReviewer(ctx context.Context, userID string, includeClosed bool) ([]model.PullRequest, error) {
+	builder := sq.Select(
 		"pr.pull_request_id",
 		"pr.pull_request_name",
 		"pr.author_id",
@@ -164,74 +601,65 @@ func (r *repo) GetByReviewer(ctx context.Context, userID string) ([]model.PullRe
 	).From("pull_requests pr").
 		Join("pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id").
 		Where(sq.Eq{"prr.reviewer_id": userID}).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
-
+		OrderBy("pr.pull_request_id").
+		PlaceholderFormat(sq.Dollar)
+	if !includeClosed {
+		builder = builder.Where(sq.NotEq{"pr.status": model.StatusClosed})
+	}
+	query, args, err := builder.ToSql()
 	if err != nil {
 		return nil, repo2.ErrToCreateToCreateSql(err)
 	}
 
-	var prs []model.PullRequest
-	err = r.sqlClient.SelectContext(ctx, &prs, query, args...)
+	var rows []pullRequestByReviewer
+	err = r.sqlClient.SelectContext(ctx, &rows, query, args...)
 	if err != nil {
 		return nil, err
 	}
-
-	if prs == nil {
-		prs = []model.PullRequest{}
-	}
-
-	return prs, nil
+	return groupPullRequests(rows), nil
 }
 
-func (r *repo) GetUserStatistics(ctx context.Context) ([]model.UserStatistics, error) {
-	query := `
-        SELECT 
-            u.user_id,
-            u.username,
-            COALESCE(u.team_name, '') as team_name,
-            u.is_active,
-            
-            COUNT(DISTINCT prr.pull_request_id) as total_review_assignments,
-            COUNT(DISTINCT CASE WHEN pr_rev.status = $1 THEN prr.pull_request_id END) as open_review_assignments,
-            COUNT(DISTINCT CASE WHEN pr_rev.status = $2 THEN prr.pull_request_id END) as merged_review_assignments,
-            
-            COUNT(DISTINCT pr_auth.pull_request_id) as total_authored_prs,
-            COUNT(DISTINCT CASE WHEN pr_auth.status = $1 THEN pr_auth.pull_request_id END) as open_authored_prs,
-            COUNT(DISTINCT CASE WHEN pr_auth.status = $2 THEN pr_auth.pull_request_id END) as merged_authored_prs
-            
-        FROM users u
-        LEFT JOIN pr_reviewers prr ON u.user_id = prr.reviewer_id
-        LEFT JOIN pull_requests pr_rev ON prr.pull_request_id = pr_rev.pull_request_id
-        LEFT JOIN pull_requests pr_auth ON u.user_id = pr_auth.author_id
-        GROUP BY u.user_id, u.username, u.team_name, u.is_active
-        ORDER BY u.user_id
-    `
-
-	var stats []model.UserStatistics
-	err := r.sqlClient.SelectContext(ctx, &stats, query, model.StatusOpen, model.StatusMerge)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user statistics: %w", err)
-	}
-
-	if stats == nil {
-		stats = []model.UserStatistics{}
-	}
-
-	return stats, nil
+var pullRequestColumns = []string{
+	"p.pull_request_id",
+	"p.pull_request_name",
+	"p.author_id",
+	"p.status",
+	"p.created_at",
+	"p.merged_at",
+	"p.closed_at",
+	"p.needs_reviewer",
+	"p.labels",
+	"COALESCE(r.reviewer_id, '') AS reviewer_id",
+	"COALESCE(ru.team_name, '') AS reviewer_team",
+	"COALESCE(ru.is_active, false) AS reviewer_active",
+	"r.assigned_at",
+	"COALESCE(r.is_fallback, false) AS is_fallback",
+	"COALESCE(r.is_code_owner, false) AS is_code_owner",
+	"COALESCE(r.matched_tags, '{}') AS matched_tags",
+	"r.due_at",
+	"COALESCE(" + isOverdue + ", false) AS is_overdue",
+	"COALESCE(v.verdict, '') AS verdict",
+	"v.created_at AS verdict_at",
 }
 
+// isOverdue expects the pull request as p, the reviewer as r and their latest verdict as v.
+const isOverdue = "p.status = 'OPEN' AND r.due_at < now() AND v.verdict IS NULL"
+
+// latestVerdictJoin joins the latest verdict of every assigned reviewer as v.
+const latestVerdictJoin = `LATERAL (
+		SELECT verdict, created_at
+		FROM pr_reviews
+		WHERE pull_request_id = r.pull_request_id AND reviewer_id = r.reviewer_id
+		ORDER BY created_at DESC, review_id DESC
+		LIMIT 1
+	) v ON true`
+
 func selectByID(ctx context.Context, selector Selector, pullRequestID string) (model.PullRequest, error) {
-	query, args, err := sq.Select(
-		"p.pull_request_id",
-		"p.pull_request_name",
-		"p.author_id",
-		"p.status",
-		"p.merged_at",
-		"r.reviewer_id",
-	).
+	query, args, err := sq.Select(pullRequestColumns...).
 		From("pull_requests p").
 		LeftJoin("pr_reviewers r ON p.pull_request_id = r.pull_request_id").
+		LeftJoin("users ru ON ru.user_id = r.reviewer_id").
+		LeftJoin(latestVerdictJoin).
 		Where(sq.Eq{"p.pull_request_id": pullRequestID}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
@@ -252,7 +680,7 @@ func (r *repo) GetByID(ctx context.Context, pullRequestID string) (model.PullReq
 	return selectByID(ctx, r.sqlClient, pullRequestID)
 }
 
-func (r *repo) ChangeReviewer(ctx context.Context, pullRequestID, oldReviewerID, reviewerID string) (model.PullRequest, error) {
+func (r *repo) ChangeReviewer(ctx context.Context, pullRequestID, oldReviewerID string, reviewer model.Reviewer, reason string, moves []model.RotationMove) (model.PullRequest, error) {
 	tx, err := r.sqlClient.Beginx()
 	if err != nil {
 		return model.PullRequest{}, err
@@ -266,22 +694,252 @@ func (r *repo) ChangeReviewer(ctx context.Context, pullRequestID, oldReviewerID,
 	}()
 
 	//Update
-	query, args, err := sq.Update("pr_reviewers").Set("reviewer_id", reviewerID).
+	err = r.changeReviewer(ctx, tx, pullRequestID, oldReviewerID, reviewer, reason)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if err = rotation.Advance(ctx, tx, moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	_, err = tx.ExecContext(ctx, refreshNeedsReviewer, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	var stored model.PullRequest
+	stored, err = selectByID(ctx, tx, pullRequestID)
+	return stored, err
+}
+
+// refreshNeedsReviewer keeps the flag only while an inactive reviewer is still assigned.
+const refreshNeedsReviewer = `
+	UPDATE pull_requests p
+	SET needs_reviewer = EXISTS (
+		SELECT 1
+		FROM pr_reviewers r
+		JOIN users u ON u.user_id = r.reviewer_id
+		WHERE r.pull_request_id = p.pull_request_id AND NOT u.is_active
+	)
+	WHERE p.pull_request_id = $1
+`
+
+// changeReviewer replaces the reviewer and records the reassignment with the given reason.
+func (r *repo) changeReviewer(ctx context.Context, tx *sqlx.Tx, pullRequestID, oldReviewerID string, reviewer model.Reviewer, reason string) error {
+	// users are locked before pull requests, in the order DeactivateReviewers takes them
+	if err := lockActiveReviewers(ctx, tx, []string{reviewer.ID}); err != nil {
+		return err
+	}
+	status, err := lockStatus(ctx, tx, pullRequestID)
+	if err != nil {
+		return err
+	}
+	// the caller checked the status before the lock, a concurrent merge or close wins
+	switch status {
+	case model.StatusMerge:
+		return ErrPRMerged
+	case model.StatusClosed:
+		return ErrPRClosed
+	}
+	query, args, err := sq.Update("pr_reviewers").
+		Set("reviewer_id", reviewer.ID).
+		Set("is_fallback", reviewer.IsFallback).
+		Set("is_code_owner", reviewer.IsCodeOwner).
+		Set("matched_tags", textArray(reviewer.MatchedTags)).
+		Set("assigned_at", time.Now()).
+		Set("due_at", nullTime(reviewer.DueAt)).
+		Set("reminded_at", nil).
+		Set("escalated_at", nil).
 		Where(sq.Eq{"pull_request_id": pullRequestID}, sq.Eq{"reviewer_id": oldReviewerID}).
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
-		return model.PullRequest{}, repo2.ErrToCreateToCreateSql(err)
+		return repo2.ErrToCreateToCreateSql(err)
 	}
 	res, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		return model.PullRequest{}, err
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%w: %s is already assigned", ErrPlanOutdated, reviewer.ID)
+		}
+		return err
 	}
 	v, err := res.RowsAffected()
 	if err != nil {
-		return model.PullRequest{}, err
+		return err
 	}
 	if v == 0 {
-		return model.PullRequest{}, ErrNoRowsAffected
+		return ErrNoRowsAffected
+	}
+	delta := statsDelta{}
+	delta.review(oldReviewerID, status, -1)
+	delta.review(reviewer.ID, status, 1)
+	if err = delta.apply(ctx, tx); err != nil {
+		return err
+	}
+	return r.recordEvents(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		Type:          model.EventReassigned,
+		ReviewerID:    reviewer.ID,
+		OldReviewerID: oldReviewerID,
+		Reason:        reason,
+	})
+}
+
+// needsReviewerEvent records that a leaving reviewer stays assigned because nobody could take over.
+func needsReviewerEvent(reassignment model.Reassignment, reason string) model.PullRequestEvent {
+	return model.PullRequestEvent{
+		PullRequestID: reassignment.PullRequestID,
+		Type:          model.EventNeedsReviewer,
+		ReviewerID:    reassignment.OldReviewerID,
+		Reason:        reason,
+	}
+}
+
+func (r *repo) GetOpenByReviewers(ctx context.Context, userIDs []string) ([]model.PullRequest, error) {
+	if len(userIDs) == 0 {
+		return []model.PullRequest{}, nil
+	}
+	reviewed := sq.Select("pull_request_id").From("pr_reviewers").Where(sq.Eq{"reviewer_id": userIDs})
+	reviewedSql, reviewedArgs, err := reviewed.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	query, args, err := sq.Select(pullRequestColumns...).
+		From("pull_requests p").
+		LeftJoin("pr_reviewers r ON p.pull_request_id = r.pull_request_id").
+		LeftJoin("users ru ON ru.user_id = r.reviewer_id").
+		LeftJoin(latestVerdictJoin).
+		Where(sq.Eq{"p.status": model.StatusOpen}).
+		Where("p.pull_request_id IN ("+reviewedSql+")", reviewedArgs...).
+		OrderBy("p.pull_request_id", "r.reviewer_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var rows []pullRequestByReviewer
+	err = r.sqlClient.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open pull requests: %w", err)
+	}
+	return groupPullRequests(rows), nil
+}
+
+// DeactivateReviewers deactivates users and applies reassignments in one transaction, planned are the
+// pull requests the reassignments were picked for. A reassignment without a new reviewer flags needs_reviewer.
+func (r *repo) DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, planned []model.PullRequest, reassignments []model.Reassignment, moves []model.RotationMove) error {
+	tx, err := r.sqlClient.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	err = r.deactivateReviewers(ctx, tx, teamName, userIDs, planned, reassignments, moves)
+	err = outdatedOnDeadlock(err)
+	return err
+}
+
+func (r *repo) deactivateReviewers(ctx context.Context, tx *sqlx.Tx, teamName string, userIDs []string, planned []model.PullRequest, reassignments []model.Reassignment, moves []model.RotationMove) error {
+	deactivate := sq.Update("users").Set("is_active", false).Where(sq.Eq{"user_id": userIDs})
+	if teamName != "" {
+		deactivate = deactivate.Where(sq.Eq{"team_name": teamName})
+	}
+	query, args, err := deactivate.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate users: %w", err)
+	}
+	deactivated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if int(deactivated) != len(userIDs) {
+		return fmt.Errorf("%w: %d of %d users deactivated", ErrPlanOutdated, deactivated, len(userIDs))
+	}
+	locked, err := lockOpenByReviewers(ctx, tx, userIDs)
+	if err != nil {
+		return err
+	}
+	if err = checkPlan(planned, locked); err != nil {
+		return err
+	}
+	if err = rotation.Advance(ctx, tx, moves); err != nil {
+		return err
+	}
+
+	for _, reassignment := range reassignments {
+		if reassignment.NewReviewerID != "" {
+			err = r.changeReviewer(ctx, tx, reassignment.PullRequestID, reassignment.OldReviewerID, model.Reviewer{
+				ID:          reassignment.NewReviewerID,
+				IsFallback:  reassignment.IsFallback,
+				MatchedTags: reassignment.MatchedTags,
+				DueAt:       reassignment.DueAt,
+			}, model.ReasonReviewerDeactivated)
+			if err != nil {
+				return fmt.Errorf("failed to reassign %s: %w", reassignment.PullRequestID, err)
+			}
+			continue
+		}
+
+		query, args, err = sq.Update("pull_requests").Set("needs_reviewer", true).
+			Where(sq.Eq{"pull_request_id": reassignment.PullRequestID, "needs_reviewer": false}).
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return repo2.ErrToCreateToCreateSql(err)
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to flag %s: %w", reassignment.PullRequestID, err)
+		}
+		flagged, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if flagged == 0 {
+			continue
+		}
+		if err = r.recordEvents(ctx, tx, needsReviewerEvent(reassignment, model.ReasonReviewerDeactivated)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type reviewLoad struct {
+	ReviewerID string `db:"reviewer_id"`
+	Count      int    `db:"open_reviews"`
+}
+
+func (r *repo) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	res := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	query, args, err := sq.Select("prr.reviewer_id", "COUNT(*) AS open_reviews").
+		From("pr_reviewers prr").
+		Join("pull_requests pr ON pr.pull_request_id = prr.pull_request_id").
+		Where(sq.Eq{"prr.reviewer_id": userIDs, "pr.status": model.StatusOpen}).
+		GroupBy("prr.reviewer_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var loads []reviewLoad
+	err = r.sqlClient.SelectContext(ctx, &loads, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+	for _, load := range loads {
+		res[load.ReviewerID] = load.Count
 	}
-	return selectByID(ctx, tx, pullRequestID)
+	return res, nil
 }
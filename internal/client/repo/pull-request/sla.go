@@ -0,0 +1,97 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type overdueReviewDB struct {
+	PullRequestID   string    `db:"pull_request_id"`
+	PullRequestName string    `db:"pull_request_name"`
+	AuthorID        string    `db:"author_id"`
+	ReviewerID      string    `db:"reviewer_id"`
+	ReviewerTeam    string    `db:"reviewer_team"`
+	AssignedAt      time.Time `db:"assigned_at"`
+	DueAt           time.Time `db:"due_at"`
+}
+
+// GetOverdue returns overdue review assignments, the longest overdue first. The team filter
+// matches the reviewer's team.
+func (r *repo) GetOverdue(ctx context.Context, filter model.OverdueFilter) ([]model.OverdueReview, error) {
+	builder := sq.Select(
+		"p.pull_request_id",
+		"p.pull_request_name",
+		"p.author_id",
+		"r.reviewer_id",
+		"COALESCE(ru.team_name, '') AS reviewer_team",
+		"r.assigned_at",
+		"r.due_at",
+	).From("pr_reviewers r").
+		Join("pull_requests p ON p.pull_request_id = r.pull_request_id").
+		Join("users ru ON ru.user_id = r.reviewer_id").
+		LeftJoin(latestVerdictJoin).
+		Where(isOverdue).
+		OrderBy("r.due_at", "p.pull_request_id", "r.reviewer_id").
+		PlaceholderFormat(sq.Dollar)
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{"ru.team_name": filter.TeamName})
+	}
+	if filter.UserID != "" {
+		builder = builder.Where(sq.Eq{"r.reviewer_id": filter.UserID})
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var rows []overdueReviewDB
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get overdue reviews: %w", err)
+	}
+	res := make([]model.OverdueReview, len(rows))
+	for i, row := range rows {
+		res[i] = model.OverdueReview(row)
+	}
+	return res, nil
+}
+
+// GetSLACompliance counts, per reviewer team, review assignments with an SLA on pull requests merged
+// within [from, to) and those whose first review came before the deadline.
+func (r *repo) GetSLACompliance(ctx context.Context, from, to time.Time, teamName string) ([]model.TeamSLACompliance, error) {
+	builder := sq.Select(
+		"COALESCE(ru.team_name, '') AS team_name",
+		"COUNT(*) AS total",
+		"COUNT(*) FILTER (WHERE fr.first_review_at <= r.due_at) AS within_sla",
+	).From("pr_reviewers r").
+		Join("pull_requests p ON p.pull_request_id = r.pull_request_id").
+		Join("users ru ON ru.user_id = r.reviewer_id").
+		LeftJoin(`LATERAL (
+			SELECT MIN(created_at) AS first_review_at
+			FROM pr_reviews
+			WHERE pull_request_id = r.pull_request_id AND reviewer_id = r.reviewer_id
+		) fr ON true`).
+		Where(sq.Eq{"p.status": model.StatusMerge}).
+		Where(sq.GtOrEq{"p.merged_at": from}, sq.Lt{"p.merged_at": to}).
+		Where("r.due_at IS NOT NULL").
+		GroupBy("ru.team_name").
+		OrderBy("team_name").
+		PlaceholderFormat(sq.Dollar)
+	if teamName != "" {
+		builder = builder.Where(sq.Eq{"ru.team_name": teamName})
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	res := make([]model.TeamSLACompliance, 0)
+	if err = r.sqlClient.SelectContext(ctx, &res, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get SLA compliance: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,173 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+// inWindow is the statistics period condition on column, the period is $1 and $2.
+func inWindow(column string) string {
+	return fmt.Sprintf("($1::timestamptz IS NULL OR %[1]s >= $1) AND ($2::timestamptz IS NULL OR %[1]s < $2)", column)
+}
+
+// userStatisticsQuery takes the team as $3 and the OPEN, MERGED and CLOSED statuses as $4-$6.
+var userStatisticsQuery = `
+	WITH reviews AS (
+		SELECT
+			r.reviewer_id AS user_id,
+			COUNT(*) FILTER (WHERE ` + inWindow("r.assigned_at") + `) AS total_review_assignments,
+			COUNT(*) FILTER (WHERE p.status = $4 AND ` + inWindow("r.assigned_at") + `) AS open_review_assignments,
+			COUNT(*) FILTER (WHERE p.status = $5 AND ` + inWindow("p.merged_at") + `) AS merged_review_assignments,
+			COUNT(*) FILTER (WHERE p.status = $6 AND ` + inWindow("r.assigned_at") + `) AS closed_review_assignments
+		FROM pr_reviewers r
+		JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+		GROUP BY r.reviewer_id
+	),
+	authored AS (
+		SELECT
+			p.author_id AS user_id,
+			COUNT(*) FILTER (WHERE ` + inWindow("p.created_at") + `) AS total_authored_prs,
+			COUNT(*) FILTER (WHERE p.status = $4 AND ` + inWindow("p.created_at") + `) AS open_authored_prs,
+			COUNT(*) FILTER (WHERE p.status = $5 AND ` + inWindow("p.merged_at") + `) AS merged_authored_prs,
+			COUNT(*) FILTER (WHERE p.status = $6 AND ` + inWindow("p.created_at") + `) AS closed_authored_prs
+		FROM pull_requests p
+		GROUP BY p.author_id
+	)
+	SELECT
+		u.user_id,
+		u.username,
+		COALESCE(u.team_name, '') AS team_name,
+		u.is_active,
+		COALESCE(rv.total_review_assignments, 0) AS total_review_assignments,
+		COALESCE(rv.open_review_assignments, 0) AS open_review_assignments,
+		COALESCE(rv.merged_review_assignments, 0) AS merged_review_assignments,
+		COALESCE(rv.closed_review_assignments, 0) AS closed_review_assignments,
+		COALESCE(au.total_authored_prs, 0) AS total_authored_prs,
+		COALESCE(au.open_authored_prs, 0) AS open_authored_prs,
+		COALESCE(au.merged_authored_prs, 0) AS merged_authored_prs,
+		COALESCE(au.closed_authored_prs, 0) AS closed_authored_prs
+	FROM users u
+	LEFT JOIN reviews rv ON rv.user_id = u.user_id
+	LEFT JOIN authored au ON au.user_id = u.user_id
+	WHERE $3::text IS NULL OR u.team_name = $3
+	ORDER BY u.user_id
+`
+
+// userStatsCountersQuery reads the all-time statistics from the user_stats counters, $1 is the team.
+var userStatsCountersQuery = `
+	SELECT
+		u.user_id,
+		u.username,
+		COALESCE(u.team_name, '') AS team_name,
+		u.is_active,
+		COALESCE(s.total_review_assignments, 0) AS total_review_assignments,
+		COALESCE(s.open_review_assignments, 0) AS open_review_assignments,
+		COALESCE(s.merged_review_assignments, 0) AS merged_review_assignments,
+		COALESCE(s.closed_review_assignments, 0) AS closed_review_assignments,
+		COALESCE(s.total_authored_prs, 0) AS total_authored_prs,
+		COALESCE(s.open_authored_prs, 0) AS open_authored_prs,
+		COALESCE(s.merged_authored_prs, 0) AS merged_authored_prs,
+		COALESCE(s.closed_authored_prs, 0) AS closed_authored_prs
+	FROM users u
+	LEFT JOIN user_stats s ON s.user_id = u.user_id
+	WHERE $1::text IS NULL OR u.team_name = $1
+	ORDER BY u.user_id
+`
+
+// GetUserStatistics reads all-time statistics from the user_stats counters and counts a period from pull requests.
+func (r *repo) GetUserStatistics(ctx context.Context, filter model.StatsFilter) ([]model.UserStatistics, error) {
+	stats := make([]model.UserStatistics, 0)
+	var err error
+	if filter.From.IsZero() && filter.To.IsZero() {
+		err = r.sqlClient.SelectContext(ctx, &stats, userStatsCountersQuery, nullIfEmpty(filter.TeamName))
+	} else {
+		err = r.sqlClient.SelectContext(ctx, &stats, userStatisticsQuery,
+			nullTime(filter.From), nullTime(filter.To), nullIfEmpty(filter.TeamName),
+			model.StatusOpen, model.StatusMerge, model.StatusClosed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// teamStatisticsQuery takes the team as $3 and the OPEN and MERGED statuses as $4 and $5.
+var teamStatisticsQuery = `
+	WITH load AS (
+		SELECT
+			u.team_name,
+			u.user_id,
+			COUNT(p.pull_request_id) AS open_reviews
+		FROM users u
+		LEFT JOIN pr_reviewers r ON r.reviewer_id = u.user_id
+		LEFT JOIN pull_requests p ON p.pull_request_id = r.pull_request_id AND p.status = $4
+		WHERE u.is_active AND u.team_name IS NOT NULL
+		GROUP BY u.team_name, u.user_id
+	),
+	authored AS (
+		SELECT
+			u.team_name,
+			COUNT(*) FILTER (WHERE ` + inWindow("p.created_at") + `) AS opened_prs,
+			COUNT(*) FILTER (WHERE p.status = $5 AND ` + inWindow("p.merged_at") + `) AS merged_prs,
+			COALESCE(SUM(rc.reviewers) FILTER (WHERE ` + inWindow("p.created_at") + `), 0) AS opened_reviewers
+		FROM pull_requests p
+		JOIN users u ON u.user_id = p.author_id
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS reviewers FROM pr_reviewers WHERE pull_request_id = p.pull_request_id
+		) rc ON true
+		WHERE u.team_name IS NOT NULL
+		GROUP BY u.team_name
+	)
+	SELECT
+		t.team_name,
+		COALESCE(a.opened_prs, 0) AS opened_prs,
+		COALESCE(a.merged_prs, 0) AS merged_prs,
+		COALESCE(a.opened_reviewers::float8 / NULLIF(a.opened_prs, 0), 0) AS avg_reviewers_per_pr,
+		COUNT(l.user_id) AS active_members,
+		COALESCE(MIN(l.open_reviews), 0) AS min_open_reviews,
+		COALESCE(MAX(l.open_reviews), 0) AS max_open_reviews,
+		COALESCE(STDDEV_POP(l.open_reviews), 0)::float8 AS stddev_open_reviews
+	FROM teams t
+	LEFT JOIN authored a ON a.team_name = t.team_name
+	LEFT JOIN load l ON l.team_name = t.team_name
+	WHERE $3::text IS NULL OR t.team_name = $3
+	GROUP BY t.team_name, a.opened_prs, a.merged_prs, a.opened_reviewers
+	ORDER BY t.team_name
+`
+
+func (r *repo) GetTeamStatistics(ctx context.Context, filter model.StatsFilter) ([]model.TeamStatistics, error) {
+	stats := make([]model.TeamStatistics, 0)
+	err := r.sqlClient.SelectContext(ctx, &stats, teamStatisticsQuery,
+		nullTime(filter.From), nullTime(filter.To), nullIfEmpty(filter.TeamName),
+		model.StatusOpen, model.StatusMerge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team statistics: %w", err)
+	}
+	return stats, nil
+}
+
+type teamCountDB struct {
+	TeamName string `db:"team_name"`
+	Count    int    `db:"count"`
+}
+
+// CountOpenReviewsByTeam sums the open review assignments from the user_stats counters by team.
+func (r *repo) CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	var rows []teamCountDB
+	err := r.sqlClient.SelectContext(ctx, &rows, `
+		SELECT COALESCE(u.team_name, '') AS team_name, SUM(s.open_review_assignments) AS count
+		FROM user_stats s
+		JOIN users u ON u.user_id = s.user_id
+		GROUP BY u.team_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews by team: %w", err)
+	}
+	res := make(map[string]int, len(rows))
+	for _, row := range rows {
+		res[row.TeamName] = row.Count
+	}
+	return res, nil
+}
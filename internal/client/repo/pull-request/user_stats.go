@@ -0,0 +1,180 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// userStatsCounters are the counter columns of user_stats.
+var userStatsCounters = []string{
+	"total_review_assignments",
+	"open_review_assignments",
+	"merged_review_assignments",
+	"closed_review_assignments",
+	"total_authored_prs",
+	"open_authored_prs",
+	"merged_authored_prs",
+	"closed_authored_prs",
+}
+
+// statsDelta accumulates the changes of user_stats counters made by one transaction, by user and column.
+type statsDelta map[string]map[string]int
+
+func (d statsDelta) add(userID, counter string, n int) {
+	if d[userID] == nil {
+		d[userID] = make(map[string]int)
+	}
+	d[userID][counter] += n
+}
+
+// review counts n review assignments of the user to pull requests in status.
+func (d statsDelta) review(userID string, status model.PullRequestStatus, n int) {
+	d.add(userID, "total_review_assignments", n)
+	d.add(userID, statusCounter(status)+"_review_assignments", n)
+}
+
+// authored counts n pull requests in status the user authored.
+func (d statsDelta) authored(userID string, status model.PullRequestStatus, n int) {
+	d.add(userID, "total_authored_prs", n)
+	d.add(userID, statusCounter(status)+"_authored_prs", n)
+}
+
+// move moves the author and the reviewers of a pull request from the counters of one status to another.
+func (d statsDelta) move(authorID string, reviewerIDs []string, from, to model.PullRequestStatus) {
+	d.add(authorID, statusCounter(from)+"_authored_prs", -1)
+	d.add(authorID, statusCounter(to)+"_authored_prs", 1)
+	for _, reviewerID := range reviewerIDs {
+		d.add(reviewerID, statusCounter(from)+"_review_assignments", -1)
+		d.add(reviewerID, statusCounter(to)+"_review_assignments", 1)
+	}
+}
+
+// apply adds the changes to user_stats, users go in a fixed order so concurrent transactions lock them alike.
+func (d statsDelta) apply(ctx context.Context, tx *sqlx.Tx) error {
+	userIDs := make([]string, 0, len(d))
+	for userID, counters := range d {
+		for _, n := range counters {
+			if n != 0 {
+				userIDs = append(userIDs, userID)
+				break
+			}
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	slices.Sort(userIDs)
+
+	builder := sq.Insert("user_stats AS s").
+		Columns(append([]string{"user_id"}, userStatsCounters...)...).
+		PlaceholderFormat(sq.Dollar)
+	for _, userID := range userIDs {
+		values := []interface{}{userID}
+		for _, counter := range userStatsCounters {
+			values = append(values, d[userID][counter])
+		}
+		builder = builder.Values(values...)
+	}
+	set := make([]string, 0, len(userStatsCounters)+1)
+	for _, counter := range userStatsCounters {
+		set = append(set, fmt.Sprintf("%[1]s = s.%[1]s + EXCLUDED.%[1]s", counter))
+	}
+	set = append(set, "updated_at = now()")
+	query, args, err := builder.Suffix("ON CONFLICT (user_id) DO UPDATE SET " + strings.Join(set, ", ")).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update user stats: %w", err)
+	}
+	return nil
+}
+
+func statusCounter(status model.PullRequestStatus) string {
+	return strings.ToLower(string(status))
+}
+
+// moveStatus moves the counters of everyone taking part in the pull request to the new status.
+func moveStatus(ctx context.Context, tx *sqlx.Tx, pullRequestID string, from, to model.PullRequestStatus) error {
+	var authorID string
+	if err := tx.GetContext(ctx, &authorID, "SELECT author_id FROM pull_requests WHERE pull_request_id = $1", pullRequestID); err != nil {
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+	var reviewerIDs []string
+	if err := tx.SelectContext(ctx, &reviewerIDs, "SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1", pullRequestID); err != nil {
+		return fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	delta := statsDelta{}
+	delta.move(authorID, reviewerIDs, from, to)
+	return delta.apply(ctx, tx)
+}
+
+// recomputeUserStats rebuilds user_stats from pull_requests and pr_reviewers.
+const recomputeUserStats = `
+	INSERT INTO user_stats (user_id, %s)
+	SELECT
+		u.user_id,
+		COALESCE(rv.total, 0), COALESCE(rv.open, 0), COALESCE(rv.merged, 0), COALESCE(rv.closed, 0),
+		COALESCE(au.total, 0), COALESCE(au.open, 0), COALESCE(au.merged, 0), COALESCE(au.closed, 0)
+	FROM users u
+	LEFT JOIN (
+		SELECT
+			r.reviewer_id AS user_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE p.status = $1) AS open,
+			COUNT(*) FILTER (WHERE p.status = $2) AS merged,
+			COUNT(*) FILTER (WHERE p.status = $3) AS closed
+		FROM pr_reviewers r
+		JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+		GROUP BY r.reviewer_id
+	) rv ON rv.user_id = u.user_id
+	LEFT JOIN (
+		SELECT
+			author_id AS user_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = $1) AS open,
+			COUNT(*) FILTER (WHERE status = $2) AS merged,
+			COUNT(*) FILTER (WHERE status = $3) AS closed
+		FROM pull_requests
+		GROUP BY author_id
+	) au ON au.user_id = u.user_id
+`
+
+// RecomputeUserStats rebuilds the user_stats counters under a table lock and returns how many users they cover.
+func (r *repo) RecomputeUserStats(ctx context.Context) (int, error) {
+	tx, err := r.sqlClient.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(ctx, "LOCK TABLE user_stats IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return 0, fmt.Errorf("failed to lock user stats: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM user_stats"); err != nil {
+		return 0, fmt.Errorf("failed to clear user stats: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(recomputeUserStats, strings.Join(userStatsCounters, ", ")),
+		model.StatusOpen, model.StatusMerge, model.StatusClosed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute user stats: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
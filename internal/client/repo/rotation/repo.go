@@ -0,0 +1,103 @@
+package rotation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrCursorMoved = errors.New("rotation cursor was moved by a concurrent assignment")
+
+// Repo keeps a per-team round-robin cursor: the user_id of the last member who got a review.
+type Repo interface {
+	GetCursor(ctx context.Context, teamName string) (string, error)
+}
+
+type repo struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Repo {
+	return &repo{
+		sqlClient: sqlClient,
+	}
+}
+
+// Advance moves the cursors inside the assigning tx, a cursor moved since planning returns ErrCursorMoved.
+func Advance(ctx context.Context, tx *sqlx.Tx, moves []model.RotationMove) error {
+	for _, move := range moves {
+		query, args, err := sq.Insert("team_rotation").Columns("team_name").Values(move.TeamName).
+			Suffix("ON CONFLICT (team_name) DO NOTHING").
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return repo2.ErrToCreateToCreateSql(err)
+		}
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to init rotation: %w", err)
+		}
+
+		query, args, err = sq.Update("team_rotation").
+			Set("last_user_id", move.To).
+			Set("updated_at", time.Now()).
+			Where(sq.Eq{"team_name": move.TeamName}).
+			Where(sq.Expr("COALESCE(last_user_id, '') = ?", move.From)).
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return repo2.ErrToCreateToCreateSql(err)
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to move rotation: %w", err)
+		}
+		moved, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			return fmt.Errorf("%w: %s", ErrCursorMoved, move.TeamName)
+		}
+	}
+	return nil
+}
+
+func (r *repo) GetCursor(ctx context.Context, teamName string) (string, error) {
+	query, args, err := sq.Select("COALESCE(last_user_id, '')").From("team_rotation").
+		Where(sq.Eq{"team_name": teamName}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return "", repo2.ErrToCreateToCreateSql(err)
+	}
+	var cursor string
+	err = r.sqlClient.GetContext(ctx, &cursor, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get rotation: %w", err)
+	}
+	return cursor, nil
+}
+
+// Order sorts ids and rotates them so that the first id after cursor comes first.
+func Order(ids []string, cursor string) []string {
+	sorted := make([]string, len(ids))
+	copy(sorted, ids)
+	sort.Strings(sorted)
+
+	start := sort.SearchStrings(sorted, cursor)
+	if start < len(sorted) && sorted[start] == cursor {
+		start++
+	}
+	if start >= len(sorted) {
+		start = 0
+	}
+	return append(sorted[start:], sorted[:start]...)
+}
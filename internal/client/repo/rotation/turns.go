@@ -0,0 +1,59 @@
+package rotation
+
+import (
+	"context"
+	"sort"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+// Turns hands out turns of the team rotations for one operation, moving the cursors only in memory.
+type Turns struct {
+	repo    Repo
+	start   map[string]string
+	cursors map[string]string
+}
+
+func NewTurns(repo Repo) *Turns {
+	return &Turns{
+		repo:    repo,
+		start:   make(map[string]string),
+		cursors: make(map[string]string),
+	}
+}
+
+// Next returns the next n candidates in turn and moves the team cursor past them.
+func (t *Turns) Next(ctx context.Context, teamName string, candidateIDs []string, n int) ([]string, error) {
+	if len(candidateIDs) == 0 || n <= 0 {
+		return []string{}, nil
+	}
+	cursor, ok := t.cursors[teamName]
+	if !ok {
+		var err error
+		cursor, err = t.repo.GetCursor(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		t.start[teamName] = cursor
+	}
+
+	picked := Order(candidateIDs, cursor)
+	if len(picked) > n {
+		picked = picked[:n]
+	}
+	t.cursors[teamName] = picked[len(picked)-1]
+	return picked, nil
+}
+
+// Moves returns the cursors moved by Next, for Advance.
+func (t *Turns) Moves() []model.RotationMove {
+	res := make([]model.RotationMove, 0, len(t.cursors))
+	for team, cursor := range t.cursors {
+		if cursor != t.start[team] {
+			res = append(res, model.RotationMove{TeamName: team, From: t.start[team], To: cursor})
+		}
+	}
+	// a fixed order keeps concurrent transactions locking the rows in the same order
+	sort.Slice(res, func(i, j int) bool { return res[i].TeamName < res[j].TeamName })
+	return res
+}
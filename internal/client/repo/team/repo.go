@@ -2,8 +2,11 @@ package team
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	repo2 "github.com/doverlof/avito_help/internal/client/repo"
@@ -15,8 +18,17 @@ import (
 )
 
 type Repo interface {
-	Add(ctx context.Context, team model.Team) error
+	Add(ctx context.Context, team model.Team) (model.TeamAddResult, error)
 	Get(ctx context.Context, name string) (model.Team, error)
+	GetSettings(ctx context.Context, name string) (model.TeamSettings, error)
+	SetSettings(ctx context.Context, settings model.TeamSettings) (model.TeamSettings, error)
+	GetFallbacks(ctx context.Context, name string) ([]string, error)
+	SetFallbacks(ctx context.Context, name string, fallbackTeams []string) error
+	GetCodeOwners(ctx context.Context, name, repository string) (model.CodeOwners, error)
+	SetCodeOwners(ctx context.Context, codeOwners model.CodeOwners) (model.CodeOwners, error)
+	Update(ctx context.Context, update model.TeamUpdate) (model.TeamUpdateResult, error)
+	Rename(ctx context.Context, name, newName string) error
+	Delete(ctx context.Context, name, moveTo string) ([]string, error)
 }
 
 type repo struct {
@@ -32,49 +44,43 @@ func New(sqlClient *sqlx.DB) Repo {
 var (
 	ErrTeamExists   = errors.New("team already exists")
 	ErrTeamNotFound = errors.New("team not found or don't have members")
-	updateUsers     = `
-        WITH data AS (
-            SELECT *
-            FROM unnest(
-                $1::text[],
-                $2::text[],
-                $3::boolean[],
-                $4::text[]
-            ) AS t(user_id, username, is_active, team_name)
-        )
-        UPDATE users AS u
-        SET 
-            username  = d.username,
-            is_active = d.is_active,
-            team_name = d.team_name
-        FROM data d
-        WHERE u.user_id = d.user_id;
+
+	ErrCodeOwnersNotFound = errors.New("CODEOWNERS file not found")
+
+	ErrMemberNotFound     = errors.New("user is not a member of the team")
+	ErrMemberDeactivation = errors.New("active user can't be deactivated by a team change")
+	ErrTeamHasOpenReviews = errors.New("team members still have open reviews")
+
+	upsertUsers = `
+        INSERT INTO users (user_id, username, is_active, team_name)
+        SELECT *
+        FROM unnest(
+            $1::text[],
+            $2::text[],
+            $3::boolean[],
+            $4::text[]
+        ) AS t(user_id, username, is_active, team_name)
+        ON CONFLICT (user_id) DO UPDATE
+        SET
+            username  = EXCLUDED.username,
+            is_active = users.is_active OR EXCLUDED.is_active,
+            team_name = EXCLUDED.team_name;
     `
 )
 
-func (r *repo) Add(ctx context.Context, team model.Team) error {
+// Add creates the team and upserts its members, see upsertMembers.
+func (r *repo) Add(ctx context.Context, team model.Team) (model.TeamAddResult, error) {
 	query, args, err := sq.Insert("teams").Columns(
 		"team_name",
 	).Values(
 		team.Name,
 	).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
-		return repo2.ErrToCreateToCreateSql(err)
-	}
-	userIDs := make([]string, len(team.Members))
-	usernames := make([]string, len(team.Members))
-	isActives := make([]bool, len(team.Members))
-	teamNames := make([]string, len(team.Members))
-
-	for i, member := range team.Members {
-		userIDs[i] = member.ID
-		usernames[i] = member.Name
-		isActives[i] = member.IsActive
-		teamNames[i] = team.Name
+		return model.TeamAddResult{}, repo2.ErrToCreateToCreateSql(err)
 	}
 	tx, err := r.sqlClient.Beginx()
 	if err != nil {
-		return err
+		return model.TeamAddResult{}, err
 	}
 	defer func() {
 		if err != nil {
@@ -87,51 +93,325 @@ func (r *repo) Add(ctx context.Context, team model.Team) error {
 	if err != nil {
 		if pqErr, ok := err.(*pgconn.PgError); ok && pqErr.Code == "23505" {
 			// unique violation
-			return ErrTeamExists
+			return model.TeamAddResult{}, ErrTeamExists
 		}
-		return err
+		return model.TeamAddResult{}, err
+	}
+
+	result, err := upsertMembers(ctx, tx, team.Name, team.Members)
+	if err != nil {
+		return model.TeamAddResult{}, err
+	}
+	result.Team = team
+	return result, nil
+}
+
+// upsertMembers inserts missing users and moves existing ones into the team, locking their rows first.
+func upsertMembers(ctx context.Context, tx *sqlx.Tx, teamName string, members []model.Member) (model.TeamAddResult, error) {
+	result := model.TeamAddResult{
+		Created: make([]string, 0),
+		Updated: make([]string, 0),
+		Moved:   make([]model.MovedMember, 0),
+	}
+	if len(members) == 0 {
+		return result, nil
+	}
+
+	userIDs := make([]string, len(members))
+	usernames := make([]string, len(members))
+	isActives := make([]bool, len(members))
+	teamNames := make([]string, len(members))
+
+	for i, member := range members {
+		userIDs[i] = member.ID
+		usernames[i] = member.Name
+		isActives[i] = member.IsActive
+		teamNames[i] = teamName
 	}
+
+	query, args, err := sq.Select("user_id", "COALESCE(team_name, '') AS team_name", "is_active").From("users").
+		Where(sq.Eq{"user_id": userIDs}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.TeamAddResult{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	var existing []struct {
+		UserID   string `db:"user_id"`
+		TeamName string `db:"team_name"`
+		IsActive bool   `db:"is_active"`
+	}
+	if err = tx.SelectContext(ctx, &existing, query, args...); err != nil {
+		return model.TeamAddResult{}, fmt.Errorf("failed to lock users: %w", err)
+	}
+	oldTeams := make(map[string]string, len(existing))
+	wasActive := make(map[string]bool, len(existing))
+	for _, u := range existing {
+		oldTeams[u.UserID] = u.TeamName
+		wasActive[u.UserID] = u.IsActive
+	}
+	for _, member := range members {
+		if wasActive[member.ID] && !member.IsActive {
+			return model.TeamAddResult{}, fmt.Errorf("%w: %s", ErrMemberDeactivation, member.ID)
+		}
+	}
+
 	_, err = tx.ExecContext(
 		ctx,
-		updateUsers,
+		upsertUsers,
 		pq.Array(userIDs),
 		pq.Array(usernames),
 		pq.Array(isActives),
 		pq.Array(teamNames),
 	)
+	if err != nil {
+		return model.TeamAddResult{}, err
+	}
+
+	for _, id := range userIDs {
+		oldTeam, ok := oldTeams[id]
+		switch {
+		case !ok:
+			result.Created = append(result.Created, id)
+		case oldTeam == "" || oldTeam == teamName:
+			result.Updated = append(result.Updated, id)
+		default:
+			result.Moved = append(result.Moved, model.MovedMember{UserID: id, FromTeam: oldTeam})
+		}
+	}
+	return result, nil
+}
+
+// Update adds and removes members of an existing team. Removed members are left without a team.
+func (r *repo) Update(ctx context.Context, update model.TeamUpdate) (model.TeamUpdateResult, error) {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return model.TeamUpdateResult{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	if err = lockTeam(ctx, tx, update.Name); err != nil {
+		return model.TeamUpdateResult{}, err
+	}
+
+	result := model.TeamUpdateResult{Removed: make([]string, 0)}
+	if len(update.Remove) > 0 {
+		var (
+			query string
+			args  []interface{}
+		)
+		query, args, err = sq.Update("users").Set("team_name", nil).
+			Where(sq.Eq{"user_id": update.Remove, "team_name": update.Name}).
+			Suffix("RETURNING user_id").
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return model.TeamUpdateResult{}, repo2.ErrToCreateToCreateSql(err)
+		}
+		if err = tx.SelectContext(ctx, &result.Removed, query, args...); err != nil {
+			return model.TeamUpdateResult{}, fmt.Errorf("failed to remove members: %w", err)
+		}
+		if len(result.Removed) != len(update.Remove) {
+			err = ErrMemberNotFound
+			return model.TeamUpdateResult{}, err
+		}
+	}
+
+	result.TeamAddResult, err = upsertMembers(ctx, tx, update.Name, update.Add)
+	if err != nil {
+		return model.TeamUpdateResult{}, err
+	}
+	return result, nil
+}
+
+// Rename changes the team name, every reference follows through ON UPDATE CASCADE.
+func (r *repo) Rename(ctx context.Context, name, newName string) error {
+	query, args, err := sq.Update("teams").Set("team_name", newName).
+		Where(sq.Eq{"team_name": name}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := r.sqlClient.ExecContext(ctx, query, args...)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok && pqErr.Code == "23505" {
+			return ErrTeamExists
+		}
+		return fmt.Errorf("failed to rename team: %w", err)
+	}
+	v, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if v == 0 {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// Delete removes the team and returns the members it moved to moveTo or released.
+func (r *repo) Delete(ctx context.Context, name, moveTo string) ([]string, error) {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	if err = lockTeam(ctx, tx, name); err != nil {
+		return nil, err
+	}
+
+	var members []string
+	if moveTo == "" {
+		var openReviews int
+		err = tx.GetContext(ctx, &openReviews, countOpenReviews, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count open reviews: %w", err)
+		}
+		if openReviews > 0 {
+			err = ErrTeamHasOpenReviews
+			return nil, err
+		}
+	}
+
+	query, args, err := sq.Update("users").Set("team_name", nilIfEmpty(moveTo)).
+		Where(sq.Eq{"team_name": name}).
+		Suffix("RETURNING user_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	if err = tx.SelectContext(ctx, &members, query, args...); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to move members: %w", err)
+	}
+
+	query, args, err = sq.Delete("teams").Where(sq.Eq{"team_name": name}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to delete team: %w", err)
+	}
+	if members == nil {
+		members = []string{}
+	}
+	return members, nil
+}
+
+const countOpenReviews = `
+	SELECT COUNT(*)
+	FROM pr_reviewers r
+	JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+	JOIN users u ON u.user_id = r.reviewer_id
+	WHERE u.team_name = $1 AND p.status = 'OPEN'
+`
+
+func lockTeam(ctx context.Context, tx *sqlx.Tx, name string) error {
+	query, args, err := sq.Select("team_name").From("teams").
+		Where(sq.Eq{"team_name": name}).Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	var locked string
+	err = tx.GetContext(ctx, &locked, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock team: %w", err)
+	}
 	return nil
 }
 
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 type user struct {
 	ID       string `db:"user_id"`
 	Name     string `db:"username"`
 	IsActive bool   `db:"is_active"`
 }
 
+type member struct {
+	user
+	AbsenceID       sql.NullInt64  `db:"absence_id"`
+	AbsenceStartsAt sql.NullTime   `db:"absence_starts_at"`
+	AbsenceEndsAt   sql.NullTime   `db:"absence_ends_at"`
+	AbsenceReason   sql.NullString `db:"absence_reason"`
+}
+
+// currentAbsence joins the absence a user is on right now, the one lasting longest if several overlap.
+const currentAbsence = `LATERAL (
+	SELECT absence_id, starts_at, ends_at, reason FROM user_absences
+	WHERE user_id = u.user_id AND starts_at <= now() AND ends_at > now()
+	ORDER BY ends_at DESC LIMIT 1
+) a ON true`
+
+// Get returns the team members together with the absence each of them is on right now.
 func (r *repo) Get(ctx context.Context, name string) (model.Team, error) {
-	query, args, err := sq.Select("user_id", "username", "is_active").From("users").
-		Where(sq.Eq{"team_name": name}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select(
+		"u.user_id",
+		"u.username",
+		"u.is_active",
+		"a.absence_id",
+		"a.starts_at AS absence_starts_at",
+		"a.ends_at AS absence_ends_at",
+		"a.reason AS absence_reason",
+	).From("users u").
+		LeftJoin(currentAbsence).
+		Where(sq.Eq{"u.team_name": name}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return model.Team{}, repo2.ErrToCreateToCreateSql(err)
 	}
-	var users []user
-	err = r.sqlClient.SelectContext(ctx, &users, query, args...)
+	var members []member
+	err = r.sqlClient.SelectContext(ctx, &members, query, args...)
 	if err != nil {
 		return model.Team{}, fmt.Errorf("failed to query users: %w", err)
 	}
 
-	if len(users) == 0 {
+	if len(members) == 0 {
 		return model.Team{}, ErrTeamNotFound
 	}
 	return model.Team{
 		Name:    name,
-		Members: convert.Many(convertUsers, users),
+		Members: convert.Many(convertMember, members),
 	}, nil
 }
 
+func convertMember(m member) model.Member {
+	res := convertUsers(m.user)
+	if m.AbsenceID.Valid {
+		res.Absence = &model.Absence{
+			ID:       m.AbsenceID.Int64,
+			UserID:   m.ID,
+			StartsAt: m.AbsenceStartsAt.Time,
+			EndsAt:   m.AbsenceEndsAt.Time,
+			Reason:   m.AbsenceReason.String,
+		}
+	}
+	return res
+}
+
 func convertUsers(user user) model.Member {
 	return model.Member{
 		ID:       user.ID,
@@ -139,3 +419,233 @@ func convertUsers(user user) model.Member {
 		IsActive: user.IsActive,
 	}
 }
+
+type teamSettings struct {
+	TeamName              string `db:"team_name"`
+	MinReviewers          int    `db:"min_reviewers"`
+	MaxReviewers          int    `db:"max_reviewers"`
+	AllowInactiveAuthor   bool   `db:"allow_inactive_author"`
+	Strategy              string `db:"strategy"`
+	RequiredApprovals     int    `db:"required_approvals"`
+	AbsenceLookaheadHours int    `db:"absence_lookahead_hours"`
+	ReviewSLAHours        int    `db:"review_sla_hours"`
+}
+
+// GetSettings returns the stored settings or the defaults when the team never changed them.
+func (r *repo) GetSettings(ctx context.Context, name string) (model.TeamSettings, error) {
+	query, args, err := sq.Select(
+		"t.team_name",
+		"ts.min_reviewers",
+		"ts.max_reviewers",
+		"ts.allow_inactive_author",
+		"COALESCE(ts.strategy, '') AS strategy",
+		"ts.required_approvals",
+		"ts.absence_lookahead_hours",
+		"ts.review_sla_hours",
+	).From("teams t").
+		LeftJoin("team_settings ts ON ts.team_name = t.team_name").
+		Where(sq.Eq{"t.team_name": name}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.TeamSettings{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var row struct {
+		TeamName            string         `db:"team_name"`
+		MinReviewers        sql.NullInt64  `db:"min_reviewers"`
+		MaxReviewers        sql.NullInt64  `db:"max_reviewers"`
+		AllowInactiveAuthor sql.NullBool   `db:"allow_inactive_author"`
+		Strategy            sql.NullString `db:"strategy"`
+		RequiredApprovals   sql.NullInt64  `db:"required_approvals"`
+		AbsenceLookahead    sql.NullInt64  `db:"absence_lookahead_hours"`
+		ReviewSLAHours      sql.NullInt64  `db:"review_sla_hours"`
+	}
+	err = r.sqlClient.GetContext(ctx, &row, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TeamSettings{}, ErrTeamNotFound
+	}
+	if err != nil {
+		return model.TeamSettings{}, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	settings := model.DefaultTeamSettings(row.TeamName)
+	if row.MinReviewers.Valid {
+		settings.MinReviewers = int(row.MinReviewers.Int64)
+		settings.MaxReviewers = int(row.MaxReviewers.Int64)
+		settings.AllowInactiveAuthor = row.AllowInactiveAuthor.Bool
+		settings.Strategy = row.Strategy.String
+		settings.RequiredApprovals = int(row.RequiredApprovals.Int64)
+		settings.AbsenceLookaheadHours = int(row.AbsenceLookahead.Int64)
+		settings.ReviewSLAHours = int(row.ReviewSLAHours.Int64)
+	}
+	return settings, nil
+}
+
+func (r *repo) SetSettings(ctx context.Context, settings model.TeamSettings) (model.TeamSettings, error) {
+	var strategy *string
+	if settings.Strategy != "" {
+		strategy = &settings.Strategy
+	}
+	query, args, err := sq.Insert("team_settings").Columns(
+		"team_name",
+		"min_reviewers",
+		"max_reviewers",
+		"allow_inactive_author",
+		"strategy",
+		"required_approvals",
+		"absence_lookahead_hours",
+		"review_sla_hours",
+		"updated_at",
+	).Values(
+		settings.TeamName,
+		settings.MinReviewers,
+		settings.MaxReviewers,
+		settings.AllowInactiveAuthor,
+		strategy,
+		settings.RequiredApprovals,
+		settings.AbsenceLookaheadHours,
+		settings.ReviewSLAHours,
+		time.Now(),
+	).Suffix(`ON CONFLICT (team_name) DO UPDATE SET
+		min_reviewers = EXCLUDED.min_reviewers,
+		max_reviewers = EXCLUDED.max_reviewers,
+		allow_inactive_author = EXCLUDED.allow_inactive_author,
+		strategy = EXCLUDED.strategy,
+		required_approvals = EXCLUDED.required_approvals,
+		absence_lookahead_hours = EXCLUDED.absence_lookahead_hours,
+		review_sla_hours = EXCLUDED.review_sla_hours,
+		updated_at = EXCLUDED.updated_at
+		RETURNING team_name, min_reviewers, max_reviewers, allow_inactive_author, COALESCE(strategy, '') AS strategy,
+		required_approvals, absence_lookahead_hours, review_sla_hours`).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.TeamSettings{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var stored teamSettings
+	err = r.sqlClient.GetContext(ctx, &stored, query, args...)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return model.TeamSettings{}, ErrTeamNotFound
+		}
+		return model.TeamSettings{}, fmt.Errorf("failed to set team settings: %w", err)
+	}
+	return model.TeamSettings(stored), nil
+}
+
+// GetFallbacks returns the ordered list of teams that lend reviewers when the team has no candidates.
+func (r *repo) GetFallbacks(ctx context.Context, name string) ([]string, error) {
+	query, args, err := sq.Select("fallback_team_name").From("team_fallbacks").
+		Where(sq.Eq{"team_name": name}).
+		OrderBy("position").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	fallbacks := make([]string, 0)
+	err = r.sqlClient.SelectContext(ctx, &fallbacks, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fallback teams: %w", err)
+	}
+	return fallbacks, nil
+}
+
+func (r *repo) SetFallbacks(ctx context.Context, name string, fallbackTeams []string) error {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	query, args, err := sq.Delete("team_fallbacks").Where(sq.Eq{"team_name": name}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to clear fallback teams: %w", err)
+	}
+	if len(fallbackTeams) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert("team_fallbacks").Columns("team_name", "fallback_team_name", "position").
+		PlaceholderFormat(sq.Dollar)
+	for i, fallback := range fallbackTeams {
+		builder = builder.Values(name, fallback, i)
+	}
+	query, args, err = builder.ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return ErrTeamNotFound
+		}
+		return fmt.Errorf("failed to set fallback teams: %w", err)
+	}
+	return nil
+}
+
+type codeOwners struct {
+	TeamName   string    `db:"team_name"`
+	Repository string    `db:"repository"`
+	Content    string    `db:"content"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+var codeOwnersColumns = []string{"team_name", "repository", "content", "updated_at"}
+
+func (r *repo) GetCodeOwners(ctx context.Context, name, repository string) (model.CodeOwners, error) {
+	query, args, err := sq.Select(codeOwnersColumns...).From("team_codeowners").
+		Where(sq.Eq{"team_name": name, "repository": repository}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.CodeOwners{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	var row codeOwners
+	if err = r.sqlClient.GetContext(ctx, &row, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CodeOwners{}, ErrCodeOwnersNotFound
+		}
+		return model.CodeOwners{}, fmt.Errorf("failed to get CODEOWNERS: %w", err)
+	}
+	return model.CodeOwners(row), nil
+}
+
+// SetCodeOwners stores the CODEOWNERS file of the team for the repository, replacing the previous one.
+func (r *repo) SetCodeOwners(ctx context.Context, co model.CodeOwners) (model.CodeOwners, error) {
+	query, args, err := sq.Insert("team_codeowners").Columns(
+		"team_name",
+		"repository",
+		"content",
+		"updated_at",
+	).Values(
+		co.TeamName,
+		co.Repository,
+		co.Content,
+		time.Now(),
+	).Suffix(`ON CONFLICT (team_name, repository) DO UPDATE SET
+		content = EXCLUDED.content,
+		updated_at = EXCLUDED.updated_at
+		RETURNING ` + strings.Join(codeOwnersColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.CodeOwners{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	var row codeOwners
+	if err = r.sqlClient.GetContext(ctx, &row, query, args...); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return model.CodeOwners{}, ErrTeamNotFound
+		}
+		return model.CodeOwners{}, fmt.Errorf("failed to set CODEOWNERS: %w", err)
+	}
+	return model.CodeOwners(row), nil
+}
@@ -0,0 +1,143 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// available matches users who are not absent now and whose absence doesn't start within lookahead.
+func available(userIDColumn string, lookahead time.Duration) sq.Sqlizer {
+	return sq.Expr(`NOT EXISTS (
+		SELECT 1 FROM user_absences a
+		WHERE a.user_id = `+userIDColumn+`
+		  AND a.ends_at > now()
+		  AND a.starts_at <= now() + make_interval(secs => ?)
+	)`, lookahead.Seconds())
+}
+
+var absenceColumns = []string{"absence_id", "user_id", "starts_at", "ends_at", "reason", "created_at"}
+
+type absenceDB struct {
+	ID        int64     `db:"absence_id"`
+	UserID    string    `db:"user_id"`
+	StartsAt  time.Time `db:"starts_at"`
+	EndsAt    time.Time `db:"ends_at"`
+	Reason    string    `db:"reason"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func convertAbsence(a absenceDB) model.Absence {
+	return model.Absence{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		StartsAt:  a.StartsAt,
+		EndsAt:    a.EndsAt,
+		Reason:    a.Reason,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// ListAbsences returns absences of the user ordered by start. Finished absences are skipped unless includePast is set.
+func (r *repo) ListAbsences(ctx context.Context, userID string, includePast bool) ([]model.Absence, error) {
+	if _, err := r.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	builder := sq.Select(absenceColumns...).
+		From("user_absences").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("starts_at", "absence_id").
+		PlaceholderFormat(sq.Dollar)
+	if !includePast {
+		builder = builder.Where("ends_at > now()")
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var rows []absenceDB
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+	return convert.Many(convertAbsence, rows), nil
+}
+
+func (r *repo) AddAbsence(ctx context.Context, absence model.Absence) (model.Absence, error) {
+	query, args, err := sq.Insert("user_absences").
+		Columns("user_id", "starts_at", "ends_at", "reason").
+		Values(absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason).
+		Suffix("RETURNING " + strings.Join(absenceColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return model.Absence{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var row absenceDB
+	err = r.sqlClient.GetContext(ctx, &row, query, args...)
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+		return model.Absence{}, ErrUserNotFound
+	}
+	if err != nil {
+		return model.Absence{}, fmt.Errorf("failed to add absence: %w", err)
+	}
+	return convertAbsence(row), nil
+}
+
+// UpdateAbsence changes the period and reason of the absence, the user stays the same.
+func (r *repo) UpdateAbsence(ctx context.Context, absence model.Absence) (model.Absence, error) {
+	query, args, err := sq.Update("user_absences").
+		Set("starts_at", absence.StartsAt).
+		Set("ends_at", absence.EndsAt).
+		Set("reason", absence.Reason).
+		Where(sq.Eq{"absence_id": absence.ID}).
+		Suffix("RETURNING " + strings.Join(absenceColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return model.Absence{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var row absenceDB
+	err = r.sqlClient.GetContext(ctx, &row, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Absence{}, ErrAbsenceNotFound
+	}
+	if err != nil {
+		return model.Absence{}, fmt.Errorf("failed to update absence: %w", err)
+	}
+	return convertAbsence(row), nil
+}
+
+func (r *repo) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	query, args, err := sq.Delete("user_absences").
+		Where(sq.Eq{"absence_id": absenceID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+
+	result, err := r.sqlClient.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAbsenceNotFound
+	}
+	return nil
+}
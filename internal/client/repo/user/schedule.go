@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/workhours"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+type scheduleDB struct {
+	UserID      string        `db:"user_id"`
+	Timezone    string        `db:"timezone"`
+	StartMinute int           `db:"start_minute"`
+	EndMinute   int           `db:"end_minute"`
+	Weekdays    pq.Int64Array `db:"weekdays"`
+}
+
+// GetSchedules returns working hours of the users, users without a schedule are left out.
+func (r *repo) GetSchedules(ctx context.Context, userIDs []string) (map[string]model.WorkSchedule, error) {
+	res := make(map[string]model.WorkSchedule, len(userIDs))
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	query, args, err := sq.Select(
+		"user_id",
+		"timezone",
+		"EXTRACT(EPOCH FROM work_start)::int / 60 AS start_minute",
+		"EXTRACT(EPOCH FROM work_end)::int / 60 AS end_minute",
+		"weekdays",
+	).From("user_schedules").
+		Where(sq.Eq{"user_id": userIDs}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var rows []scheduleDB
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	for _, row := range rows {
+		weekdays := make([]time.Weekday, len(row.Weekdays))
+		for i, day := range row.Weekdays {
+			weekdays[i] = time.Weekday(day % 7)
+		}
+		res[row.UserID] = model.WorkSchedule{
+			Timezone:    row.Timezone,
+			StartMinute: row.StartMinute,
+			EndMinute:   row.EndMinute,
+			Weekdays:    weekdays,
+		}
+	}
+	return res, nil
+}
+
+// SetSchedule replaces the working hours of the user.
+func (r *repo) SetSchedule(ctx context.Context, userID string, schedule model.WorkSchedule) error {
+	weekdays := make([]int64, len(schedule.Weekdays))
+	for i, day := range schedule.Weekdays {
+		weekdays[i] = int64(day)
+		if day == time.Sunday {
+			weekdays[i] = 7
+		}
+	}
+	query, args, err := sq.Insert("user_schedules").
+		Columns("user_id", "timezone", "work_start", "work_end", "weekdays", "updated_at").
+		Values(
+			userID,
+			schedule.Timezone,
+			workhours.FormatClock(schedule.StartMinute),
+			workhours.FormatClock(schedule.EndMinute),
+			pq.Array(weekdays),
+			time.Now(),
+		).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+		timezone = EXCLUDED.timezone,
+		work_start = EXCLUDED.work_start,
+		work_end = EXCLUDED.work_end,
+		weekdays = EXCLUDED.weekdays,
+		updated_at = EXCLUDED.updated_at`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+
+	if _, err = r.sqlClient.ExecContext(ctx, query, args...); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to set schedule: %w", err)
+	}
+	return nil
+}
@@ -2,20 +2,34 @@ package user
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	repo2 "github.com/doverlof/avito_help/internal/client/repo"
 	"github.com/doverlof/avito_help/internal/convert"
 	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 )
 
 type Repo interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error)
 	GetByID(ctx context.Context, userID string) (model.User, error)
-	GetReviewersByAuthorID(ctx context.Context, authorID string) ([]model.User, error)
+	GetReviewersByAuthorID(ctx context.Context, authorID string, lookahead time.Duration) ([]model.User, error)
+	GetActiveByTeam(ctx context.Context, teamName string, lookahead time.Duration) ([]model.User, error)
+	GetActiveByCodeOwners(ctx context.Context, logins []string, teams []string, lookahead time.Duration) ([]model.User, error)
+	GetSkills(ctx context.Context, userIDs []string) (map[string][]model.Skill, error)
+	SetSkills(ctx context.Context, userID string, skills []model.Skill) error
+	ListAbsences(ctx context.Context, userID string, includePast bool) ([]model.Absence, error)
+	AddAbsence(ctx context.Context, absence model.Absence) (model.Absence, error)
+	UpdateAbsence(ctx context.Context, absence model.Absence) (model.Absence, error)
+	DeleteAbsence(ctx context.Context, absenceID int64) error
+	GetSchedules(ctx context.Context, userIDs []string) (map[string]model.WorkSchedule, error)
+	SetSchedule(ctx context.Context, userID string, schedule model.WorkSchedule) error
 }
 
 type repo struct {
@@ -31,6 +45,7 @@ func New(sqlClient *sqlx.DB) Repo {
 var (
 	ErrUserNotFound         = errors.New("user not found")
 	ErrTeamOrAuthorNotFound = errors.New("team or author not found")
+	ErrAbsenceNotFound      = errors.New("absence not found")
 )
 
 type userDB struct {
@@ -68,7 +83,7 @@ func (r *repo) SetIsActive(ctx context.Context, userID string, isActive bool) (m
 }
 
 func (r *repo) GetByID(ctx context.Context, userID string) (model.User, error) {
-	query, args, err := sq.Select("user_id", "username", "team_name", "is_active").
+	query, args, err := sq.Select("user_id", "username", "COALESCE(team_name, '') AS team_name", "is_active").
 		From("users").
 		Where(sq.Eq{"user_id": userID}).
 		PlaceholderFormat(sq.Dollar).
@@ -79,6 +94,9 @@ func (r *repo) GetByID(ctx context.Context, userID string) (model.User, error) {
 
 	var user userDB
 	err = r.sqlClient.GetContext(ctx, &user, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrUserNotFound
+	}
 	if err != nil {
 		return model.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -95,12 +113,15 @@ func convertUser(u userDB) model.User {
 	}
 }
 
-func (r *repo) GetReviewersByAuthorID(ctx context.Context, authorID string) ([]model.User, error) {
+// GetReviewersByAuthorID returns active teammates of the author who are not absent now
+// and whose absence doesn't start within lookahead.
+func (r *repo) GetReviewersByAuthorID(ctx context.Context, authorID string, lookahead time.Duration) ([]model.User, error) {
 	subQuery := sq.Select("team_name").From("users").
 		Where(sq.Eq{"user_id": authorID})
 	query, args, err := sq.Select("user_id", "username", "team_name", "is_active").
 		From("users").
 		Where(sq.Expr("team_name = (?)", subQuery), sq.NotEq{"user_id": authorID}, sq.Eq{"is_active": true}).
+		Where(available("users.user_id", lookahead)).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
@@ -115,3 +136,148 @@ func (r *repo) GetReviewersByAuthorID(ctx context.Context, authorID string) ([]m
 	}
 	return convert.Many(convertUser, users), nil
 }
+
+// GetActiveByTeam returns active members of the team who are available, see GetReviewersByAuthorID.
+func (r *repo) GetActiveByTeam(ctx context.Context, teamName string, lookahead time.Duration) ([]model.User, error) {
+	query, args, err := sq.Select("user_id", "username", "team_name", "is_active").
+		From("users").
+		Where(sq.Eq{"team_name": teamName, "is_active": true}).
+		Where(available("users.user_id", lookahead)).
+		OrderBy("user_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return []model.User{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var users []userDB
+	err = r.sqlClient.SelectContext(ctx, &users, query, args...)
+	if err != nil {
+		return []model.User{}, fmt.Errorf("failed to get team users: %w", err)
+	}
+	return convert.Many(convertUser, users), nil
+}
+
+// GetActiveByCodeOwners returns active users named by CODEOWNERS: a login matches a linked GitHub login
+// or the user id itself, a team matches every member of the team with that name. Absent users are skipped.
+func (r *repo) GetActiveByCodeOwners(ctx context.Context, logins []string, teams []string, lookahead time.Duration) ([]model.User, error) {
+	if len(logins) == 0 && len(teams) == 0 {
+		return []model.User{}, nil
+	}
+	lowerLogins := make([]string, len(logins))
+	for i, login := range logins {
+		lowerLogins[i] = strings.ToLower(login)
+	}
+	query, args, err := sq.Select("u.user_id", "u.username", "COALESCE(u.team_name, '') AS team_name", "u.is_active").
+		From("users u").
+		LeftJoin("github_users gu ON gu.user_id = u.user_id").
+		Where(sq.Eq{"u.is_active": true}).
+		Where(available("u.user_id", lookahead)).
+		Where(sq.Or{
+			sq.Eq{"gu.login": lowerLogins},
+			sq.Eq{"u.user_id": logins},
+			sq.Eq{"u.team_name": teams},
+		}).
+		OrderBy("u.user_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return []model.User{}, repo2.ErrToCreateToCreateSql(err)
+	}
+
+	var users []userDB
+	err = r.sqlClient.SelectContext(ctx, &users, query, args...)
+	if err != nil {
+		return []model.User{}, fmt.Errorf("failed to get code owners: %w", err)
+	}
+	return convert.Many(convertUser, users), nil
+}
+
+type skillDB struct {
+	UserID string        `db:"user_id"`
+	Tag    string        `db:"tag"`
+	Level  sql.NullInt64 `db:"level"`
+}
+
+// GetSkills returns the skills of the users ordered by tag, users without skills are left out.
+func (r *repo) GetSkills(ctx context.Context, userIDs []string) (map[string][]model.Skill, error) {
+	res := make(map[string][]model.Skill, len(userIDs))
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	query, args, err := sq.Select("user_id", "tag", "level").
+		From("user_skills").
+		Where(sq.Eq{"user_id": userIDs}).
+		OrderBy("user_id", "tag").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []skillDB
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get user skills: %w", err)
+	}
+	for _, row := range rows {
+		res[row.UserID] = append(res[row.UserID], model.Skill{
+			Tag:   row.Tag,
+			Level: int(row.Level.Int64),
+		})
+	}
+	return res, nil
+}
+
+// SetSkills replaces the skills of the user.
+func (r *repo) SetSkills(ctx context.Context, userID string, skills []model.Skill) error {
+	tx, err := r.sqlClient.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	query, args, err := sq.Delete("user_skills").Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to clear user skills: %w", err)
+	}
+	if len(skills) == 0 {
+		var exists bool
+		if err = tx.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1)", userID); err != nil {
+			return err
+		}
+		if !exists {
+			err = ErrUserNotFound
+		}
+		return err
+	}
+
+	builder := sq.Insert("user_skills").Columns("user_id", "tag", "level").PlaceholderFormat(sq.Dollar)
+	for _, skill := range skills {
+		var level interface{}
+		if skill.Level != 0 {
+			level = skill.Level
+		}
+		builder = builder.Values(userID, skill.Tag, level)
+	}
+	query, args, err = builder.ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			err = ErrUserNotFound
+			return err
+		}
+		return fmt.Errorf("failed to set user skills: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	repo2 "github.com/doverlof/avito_help/internal/client/repo"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+)
+
+// Repo stores webhook subscriptions and the delivery outbox filled by the pull request repo.
+type Repo interface {
+	Create(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	List(ctx context.Context) ([]model.WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, attempt int, statusCode int) error
+	Reschedule(ctx context.Context, id int64, attempt int, statusCode int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, attempt int, statusCode int, lastError string) error
+}
+
+type repo struct {
+	sqlClient *sqlx.DB
+}
+
+func New(sqlClient *sqlx.DB) Repo {
+	return &repo{
+		sqlClient: sqlClient,
+	}
+}
+
+type subscription struct {
+	ID         int64          `db:"subscription_id"`
+	URL        string         `db:"url"`
+	Secret     string         `db:"secret"`
+	EventTypes pq.StringArray `db:"event_types"`
+	IsActive   bool           `db:"is_active"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func convertSubscription(row subscription) model.WebhookSubscription {
+	eventTypes := make([]model.PullRequestEventType, len(row.EventTypes))
+	for i, eventType := range row.EventTypes {
+		eventTypes[i] = model.PullRequestEventType(eventType)
+	}
+	return model.WebhookSubscription{
+		ID:         row.ID,
+		URL:        row.URL,
+		Secret:     row.Secret,
+		EventTypes: eventTypes,
+		IsActive:   row.IsActive,
+		CreatedAt:  row.CreatedAt,
+	}
+}
+
+var subscriptionColumns = []string{"subscription_id", "url", "secret", "event_types", "is_active", "created_at"}
+
+func (r *repo) Create(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+	eventTypes := make([]string, len(sub.EventTypes))
+	for i, eventType := range sub.EventTypes {
+		eventTypes[i] = string(eventType)
+	}
+	query, args, err := sq.Insert("webhook_subscriptions").Columns(
+		"url",
+		"secret",
+		"event_types",
+	).Values(
+		sub.URL,
+		sub.Secret,
+		pq.Array(eventTypes),
+	).Suffix("RETURNING " + strings.Join(subscriptionColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.WebhookSubscription{}, repo2.ErrToCreateToCreateSql(err)
+	}
+	var row subscription
+	if err = r.sqlClient.GetContext(ctx, &row, query, args...); err != nil {
+		return model.WebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return convertSubscription(row), nil
+}
+
+func (r *repo) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	query, args, err := sq.Select(subscriptionColumns...).From("webhook_subscriptions").
+		OrderBy("subscription_id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []subscription
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	res := make([]model.WebhookSubscription, len(rows))
+	for i, row := range rows {
+		res[i] = convertSubscription(row)
+	}
+	return res, nil
+}
+
+// Delete removes the subscription together with its delivery log.
+func (r *repo) Delete(ctx context.Context, id int64) error {
+	query, args, err := sq.Delete("webhook_subscriptions").
+		Where(sq.Eq{"subscription_id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	res, err := r.sqlClient.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	v, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if v == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+type delivery struct {
+	ID             int64          `db:"delivery_id"`
+	SubscriptionID int64          `db:"subscription_id"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	LastStatusCode sql.NullInt64  `db:"last_status_code"`
+	LastError      sql.NullString `db:"last_error"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+
+	EventID       int64          `db:"event_id"`
+	PullRequestID string         `db:"pull_request_id"`
+	EventType     string         `db:"event_type"`
+	Actor         sql.NullString `db:"actor"`
+	ReviewerID    sql.NullString `db:"reviewer_id"`
+	OldReviewerID sql.NullString `db:"old_reviewer_id"`
+	FromStatus    sql.NullString `db:"from_status"`
+	ToStatus      sql.NullString `db:"to_status"`
+	Verdict       sql.NullString `db:"verdict"`
+	Reason        sql.NullString `db:"reason"`
+	EventAt       time.Time      `db:"event_created_at"`
+}
+
+var deliveryColumns = []string{
+	"d.delivery_id",
+	"d.subscription_id",
+	"s.url",
+	"s.secret",
+	"d.status",
+	"d.attempts",
+	"d.last_status_code",
+	"d.last_error",
+	"d.next_attempt_at",
+	"d.delivered_at",
+	"d.created_at",
+	"e.event_id",
+	"e.pull_request_id",
+	"e.event_type",
+	"e.actor",
+	"e.reviewer_id",
+	"e.old_reviewer_id",
+	"e.from_status",
+	"e.to_status",
+	"e.verdict",
+	"e.reason",
+	"e.created_at AS event_created_at",
+}
+
+func convertDelivery(row delivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		URL:            row.URL,
+		Secret:         row.Secret,
+		Status:         model.WebhookDeliveryStatus(row.Status),
+		Attempts:       row.Attempts,
+		LastStatusCode: int(row.LastStatusCode.Int64),
+		LastError:      row.LastError.String,
+		NextAttemptAt:  row.NextAttemptAt,
+		DeliveredAt:    row.DeliveredAt.Time,
+		CreatedAt:      row.CreatedAt,
+		Event: model.PullRequestEvent{
+			ID:            row.EventID,
+			PullRequestID: row.PullRequestID,
+			Type:          model.PullRequestEventType(row.EventType),
+			Actor:         row.Actor.String,
+			ReviewerID:    row.ReviewerID.String,
+			OldReviewerID: row.OldReviewerID.String,
+			FromStatus:    model.PullRequestStatus(row.FromStatus.String),
+			ToStatus:      model.PullRequestStatus(row.ToStatus.String),
+			Verdict:       model.ReviewVerdict(row.Verdict.String),
+			Reason:        row.Reason.String,
+			CreatedAt:     row.EventAt,
+		},
+	}
+}
+
+func convertDeliveries(rows []delivery) []model.WebhookDelivery {
+	res := make([]model.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		res[i] = convertDelivery(row)
+	}
+	return res
+}
+
+// ListDeliveries returns the delivery log, newest first.
+func (r *repo) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	builder := sq.Select(deliveryColumns...).
+		From("webhook_deliveries d").
+		Join("webhook_subscriptions s ON s.subscription_id = d.subscription_id").
+		Join("pr_events e ON e.event_id = d.event_id").
+		OrderBy("d.delivery_id DESC").
+		Limit(uint64(filter.Limit)).
+		PlaceholderFormat(sq.Dollar)
+	if filter.SubscriptionID != 0 {
+		builder = builder.Where(sq.Eq{"d.subscription_id": filter.SubscriptionID})
+	}
+	if filter.Status != "" {
+		builder = builder.Where(sq.Eq{"d.status": filter.Status})
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, repo2.ErrToCreateToCreateSql(err)
+	}
+	var rows []delivery
+	if err = r.sqlClient.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return convertDeliveries(rows), nil
+}
+
+// claimDeliveries leases due deliveries: the attempt is counted and next_attempt_at is pushed by the
+// lease, so another instance only picks a delivery up again if this one dies before reporting back.
+const claimDeliveries = `
+	WITH claimed AS (
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+		    next_attempt_at = now() + make_interval(secs => $2)
+		WHERE d.delivery_id IN (
+			SELECT delivery_id
+			FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.*
+	)
+	SELECT %s
+	FROM claimed d
+	JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+	JOIN pr_events e ON e.event_id = d.event_id
+	ORDER BY d.delivery_id
+`
+
+func (r *repo) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	query := fmt.Sprintf(claimDeliveries, strings.Join(deliveryColumns, ", "))
+	var rows []delivery
+	if err := r.sqlClient.SelectContext(ctx, &rows, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return convertDeliveries(rows), nil
+}
+
+func (r *repo) MarkDelivered(ctx context.Context, id int64, attempt int, statusCode int) error {
+	return r.update(ctx, id, attempt, map[string]interface{}{
+		"status":           model.DeliveryDelivered,
+		"last_status_code": statusCode,
+		"last_error":       nil,
+		"delivered_at":     time.Now(),
+	})
+}
+
+func (r *repo) Reschedule(ctx context.Context, id int64, attempt int, statusCode int, lastError string, nextAttemptAt time.Time) error {
+	return r.update(ctx, id, attempt, map[string]interface{}{
+		"last_status_code": nullIfZero(statusCode),
+		"last_error":       lastError,
+		"next_attempt_at":  nextAttemptAt,
+	})
+}
+
+func (r *repo) MarkFailed(ctx context.Context, id int64, attempt int, statusCode int, lastError string) error {
+	return r.update(ctx, id, attempt, map[string]interface{}{
+		"status":           model.DeliveryFailed,
+		"last_status_code": nullIfZero(statusCode),
+		"last_error":       lastError,
+	})
+}
+
+// update reports the outcome of the claim that counted attempt. A delivery claimed again after the
+// lease ran out belongs to the newer claim and is left alone.
+func (r *repo) update(ctx context.Context, id int64, attempt int, values map[string]interface{}) error {
+	query, args, err := sq.Update("webhook_deliveries").SetMap(values).
+		Where(sq.Eq{"delivery_id": id, "attempts": attempt, "status": model.DeliveryPending}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return repo2.ErrToCreateToCreateSql(err)
+	}
+	if _, err = r.sqlClient.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update webhook delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+func nullIfZero(v int) *int {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,131 @@
+// Package codehost mirrors reviewer assignments to the code host the pull requests come from.
+package codehost
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	codeHostClient "github.com/doverlof/avito_help/internal/client/codehost"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/webhook"
+)
+
+// Store is the sync queue the syncer works through.
+type Store interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.CodeHostSync, error)
+	DesiredReviewers(ctx context.Context, pullRequestID string) ([]string, error)
+	MarkSynced(ctx context.Context, pullRequestID string, attempt int, revision int64, reviewers []string) error
+	MarkSkipped(ctx context.Context, pullRequestID string, attempt int, reason string) error
+	Reschedule(ctx context.Context, pullRequestID string, attempt int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, pullRequestID string, attempt int, lastError string) error
+}
+
+type Syncer struct {
+	store     Store
+	client    codeHostClient.CodeHostClient
+	cfg       config.CodeHostConfig
+	component *health.Component
+}
+
+func NewSyncer(cfg config.CodeHostConfig, store Store, client codeHostClient.CodeHostClient, component *health.Component) *Syncer {
+	return &Syncer{
+		store:     store,
+		client:    client,
+		cfg:       cfg,
+		component: component,
+	}
+}
+
+// NewGitHubSyncer builds a syncer that talks to the GitHub REST API from cfg.
+func NewGitHubSyncer(cfg config.CodeHostConfig, store Store, component *health.Component) *Syncer {
+	client := codeHostClient.NewGitHub(cfg.BaseURL, cfg.Token, &http.Client{Timeout: cfg.Timeout})
+	return NewSyncer(cfg, store, client, component)
+}
+
+// Run polls the sync queue until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		_, err := s.SyncDue(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("code host sync:", err)
+		}
+		s.component.Ran(err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncDue pushes up to BatchSize due syncs and returns how many were attempted. Like webhook
+// deliveries, every sync is claimed right before it is pushed, the lease covers its two requests.
+func (s *Syncer) SyncDue(ctx context.Context) (int, error) {
+	for n := 0; n < s.cfg.BatchSize; n++ {
+		syncs, err := s.store.Claim(ctx, 1, 3*s.cfg.Timeout)
+		if err != nil || len(syncs) == 0 {
+			return n, err
+		}
+		if err = s.push(ctx, syncs[0]); err != nil {
+			return n + 1, err
+		}
+	}
+	return s.cfg.BatchSize, nil
+}
+
+func (s *Syncer) push(ctx context.Context, sync model.CodeHostSync) error {
+	ref, ok := codeHostClient.ParsePullRequestID(sync.PullRequestID)
+	if !ok {
+		return s.store.MarkSkipped(ctx, sync.PullRequestID, sync.Attempts, "pull request id is not <owner>/<repo>#<number>")
+	}
+	err := s.sync(ctx, ref, sync)
+	if err == nil {
+		return nil
+	}
+	var statusErr *codeHostClient.StatusError
+	if (errors.As(err, &statusErr) && !statusErr.Temporary()) || sync.Attempts >= s.cfg.MaxAttempts {
+		return s.store.MarkFailed(ctx, sync.PullRequestID, sync.Attempts, err.Error())
+	}
+	next := time.Now().Add(webhook.Backoff(s.cfg.BaseBackoff, s.cfg.MaxBackoff, sync.Attempts))
+	return s.store.Reschedule(ctx, sync.PullRequestID, sync.Attempts, err.Error(), next)
+}
+
+// sync withdraws reviews of logins that are no longer assigned and requests the new ones.
+func (s *Syncer) sync(ctx context.Context, ref codeHostClient.PullRequestRef, sync model.CodeHostSync) error {
+	desired, err := s.store.DesiredReviewers(ctx, sync.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if err = s.client.RemoveReviewers(ctx, ref, difference(sync.SyncedReviewers, desired)); err != nil {
+		return err
+	}
+	if err = s.client.RequestReviewers(ctx, ref, difference(desired, sync.SyncedReviewers)); err != nil {
+		return err
+	}
+	return s.store.MarkSynced(ctx, sync.PullRequestID, sync.Attempts, sync.Revision, desired)
+}
+
+// difference returns the elements of a missing from b, keeping their order.
+func difference(a, b []string) []string {
+	skip := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		skip[v] = struct{}{}
+	}
+	res := make([]string, 0)
+	for _, v := range a {
+		if _, ok := skip[v]; !ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,294 @@
+package codehost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	codeHostClient "github.com/doverlof/avito_help/internal/client/codehost"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryQueue mimics the code_host_syncs table.
+type memoryQueue struct {
+	mu        sync.Mutex
+	syncs     map[string]*model.CodeHostSync
+	reviewers map[string][]string
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{
+		syncs:     map[string]*model.CodeHostSync{},
+		reviewers: map[string][]string{},
+	}
+}
+
+// assign replaces the reviewers of the pull request and queues the sync, like recordEvents does.
+func (q *memoryQueue) assign(pullRequestID string, logins ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reviewers[pullRequestID] = logins
+	s, ok := q.syncs[pullRequestID]
+	if !ok {
+		q.syncs[pullRequestID] = &model.CodeHostSync{PullRequestID: pullRequestID, Status: model.SyncPending, Revision: 1}
+		return
+	}
+	s.Revision++
+	if s.Status != model.SyncPending {
+		s.Status, s.Attempts, s.NextAttemptAt = model.SyncPending, 0, time.Time{}
+	}
+}
+
+func (q *memoryQueue) Claim(_ context.Context, limit int, lease time.Duration) ([]model.CodeHostSync, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	res := make([]model.CodeHostSync, 0)
+	for _, s := range q.syncs {
+		if len(res) == limit {
+			break
+		}
+		if s.Status == model.SyncPending && !s.NextAttemptAt.After(time.Now()) {
+			s.Attempts++
+			s.NextAttemptAt = time.Now().Add(lease)
+			res = append(res, *s)
+		}
+	}
+	return res, nil
+}
+
+func (q *memoryQueue) DesiredReviewers(_ context.Context, pullRequestID string) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.reviewers[pullRequestID], nil
+}
+
+// claimed returns the sync unless it was claimed again since attempt, like the conditional update does.
+func (q *memoryQueue) claimed(pullRequestID string, attempt int) *model.CodeHostSync {
+	s := q.syncs[pullRequestID]
+	if s.Attempts != attempt || s.Status != model.SyncPending {
+		return &model.CodeHostSync{}
+	}
+	return s
+}
+
+func (q *memoryQueue) MarkSynced(_ context.Context, pullRequestID string, attempt int, revision int64, reviewers []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.claimed(pullRequestID, attempt)
+	s.SyncedReviewers, s.LastError, s.NextAttemptAt = reviewers, "", time.Time{}
+	if s.Revision == revision {
+		s.Status = model.SyncSynced
+	} else {
+		s.Attempts = 0
+	}
+	return nil
+}
+
+func (q *memoryQueue) MarkSkipped(_ context.Context, pullRequestID string, attempt int, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.claimed(pullRequestID, attempt)
+	s.Status, s.LastError = model.SyncSkipped, reason
+	return nil
+}
+
+func (q *memoryQueue) Reschedule(_ context.Context, pullRequestID string, attempt int, lastError string, nextAttemptAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.claimed(pullRequestID, attempt)
+	s.LastError, s.NextAttemptAt = lastError, nextAttemptAt
+	return nil
+}
+
+func (q *memoryQueue) MarkFailed(_ context.Context, pullRequestID string, attempt int, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.claimed(pullRequestID, attempt)
+	s.Status, s.LastError = model.SyncFailed, lastError
+	return nil
+}
+
+func (q *memoryQueue) get(pullRequestID string) model.CodeHostSync {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return *q.syncs[pullRequestID]
+}
+
+type gitHubCall struct {
+	Method    string
+	Path      string
+	Reviewers []string
+}
+
+// fakeGitHub records requested_reviewers calls and answers with the queued status codes, then 201.
+type fakeGitHub struct {
+	mu      sync.Mutex
+	calls   []gitHubCall
+	answers []int
+	// onCall runs at the start of every call, e.g. to act as another instance.
+	onCall func()
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.onCall != nil {
+		f.onCall()
+	}
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.calls = append(f.calls, gitHubCall{Method: r.Method, Path: r.URL.Path, Reviewers: body.Reviewers})
+
+	status := http.StatusCreated
+	if len(f.answers) > 0 {
+		status, f.answers = f.answers[0], f.answers[1:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status >= 300 {
+		_, _ = w.Write([]byte(`{"message":"Reviews may only be requested from collaborators."}`))
+	}
+}
+
+func (f *fakeGitHub) recorded() []gitHubCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]gitHubCall(nil), f.calls...)
+}
+
+func newTestSyncer(t *testing.T, answers ...int) (*Syncer, *memoryQueue, *fakeGitHub) {
+	fake := &fakeGitHub{answers: answers}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := config.CodeHostConfig{
+		BaseURL:     server.URL,
+		Token:       "test-token",
+		BatchSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}
+	queue := newMemoryQueue()
+	return NewGitHubSyncer(cfg, queue, nil), queue, fake
+}
+
+func syncAll(t *testing.T, s *Syncer) {
+	for i := 0; i < 10; i++ {
+		n, err := s.SyncDue(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+}
+
+func TestSyncRequestsAndRemovesReviewers(t *testing.T) {
+	s, queue, fake := newTestSyncer(t)
+
+	queue.assign("acme/api#7", "alice", "bob")
+	syncAll(t, s)
+	queue.assign("acme/api#7", "bob", "carol")
+	syncAll(t, s)
+
+	path := "/repos/acme/api/pulls/7/requested_reviewers"
+	assert.Equal(t, []gitHubCall{
+		{Method: http.MethodPost, Path: path, Reviewers: []string{"alice", "bob"}},
+		{Method: http.MethodDelete, Path: path, Reviewers: []string{"alice"}},
+		{Method: http.MethodPost, Path: path, Reviewers: []string{"carol"}},
+	}, fake.recorded())
+
+	sync := queue.get("acme/api#7")
+	assert.Equal(t, model.SyncSynced, sync.Status)
+	assert.Equal(t, []string{"bob", "carol"}, sync.SyncedReviewers)
+}
+
+func TestSyncRetriesTemporaryErrors(t *testing.T) {
+	s, queue, fake := newTestSyncer(t, http.StatusBadGateway, http.StatusTooManyRequests)
+
+	queue.assign("acme/api#7", "alice")
+	syncAll(t, s)
+
+	assert.Len(t, fake.recorded(), 3)
+	sync := queue.get("acme/api#7")
+	assert.Equal(t, model.SyncSynced, sync.Status)
+	assert.Equal(t, 3, sync.Attempts)
+}
+
+func TestSyncGivesUp(t *testing.T) {
+	s, queue, fake := newTestSyncer(t, http.StatusUnprocessableEntity)
+
+	queue.assign("acme/api#7", "alice")
+	syncAll(t, s)
+
+	assert.Len(t, fake.recorded(), 1, "a refused request is not retried")
+	sync := queue.get("acme/api#7")
+	assert.Equal(t, model.SyncFailed, sync.Status)
+	assert.Contains(t, sync.LastError, "422")
+
+	s, queue, fake = newTestSyncer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	queue.assign("acme/api#7", "alice")
+	syncAll(t, s)
+
+	assert.Len(t, fake.recorded(), 3)
+	assert.Equal(t, model.SyncFailed, queue.get("acme/api#7").Status)
+}
+
+func TestSyncSkipsLocalPullRequests(t *testing.T) {
+	s, queue, fake := newTestSyncer(t)
+
+	queue.assign("pr-1001", "alice")
+	syncAll(t, s)
+
+	assert.Empty(t, fake.recorded())
+	assert.Equal(t, model.SyncSkipped, queue.get("pr-1001").Status)
+}
+
+func TestSyncKeepsReclaimedSync(t *testing.T) {
+	s, queue, fake := newTestSyncer(t)
+	fake.onCall = func() {
+		// the lease runs out during the request and another instance claims the sync
+		queue.mu.Lock()
+		queue.syncs["acme/api#7"].NextAttemptAt = time.Now()
+		queue.mu.Unlock()
+		_, err := queue.Claim(context.Background(), 1, time.Minute)
+		require.NoError(t, err)
+		fake.onCall = nil
+	}
+
+	queue.assign("acme/api#7", "alice")
+	n, err := s.SyncDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	sync := queue.get("acme/api#7")
+	assert.Equal(t, model.SyncPending, sync.Status, "the late report of the first claim is dropped")
+	assert.Equal(t, 2, sync.Attempts)
+	assert.Empty(t, sync.SyncedReviewers)
+}
+
+func TestParsePullRequestID(t *testing.T) {
+	ref, ok := codeHostClient.ParsePullRequestID("acme/api#7")
+	require.True(t, ok)
+	assert.Equal(t, codeHostClient.PullRequestRef{Owner: "acme", Repo: "api", Number: 7}, ref)
+	assert.Equal(t, "acme/api#7", ref.ID())
+
+	for _, id := range []string{"pr-1001", "acme#7", "acme/api#x", "a/b/c#1", "/api#1"} {
+		_, ok = codeHostClient.ParsePullRequestID(id)
+		assert.False(t, ok, id)
+	}
+}
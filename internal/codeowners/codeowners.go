@@ -0,0 +1,160 @@
+// Package codeowners parses GitHub CODEOWNERS files and resolves the owners of changed paths.
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidPattern = errors.New("invalid CODEOWNERS pattern")
+
+// Rule is one line of a CODEOWNERS file. A rule without owners removes ownership of the matched paths.
+type Rule struct {
+	Line    int
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	Rules []Rule
+}
+
+// Parse reads a CODEOWNERS file, rejecting negated patterns and character ranges like GitHub does.
+func Parse(content string) (Ruleset, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		re, err := compile(pattern)
+		if err != nil {
+			return Ruleset{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		rules = append(rules, Rule{
+			Line:    line,
+			Pattern: pattern,
+			Owners:  fields[1:],
+			re:      re,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return Ruleset{}, err
+	}
+	return Ruleset{Rules: rules}, nil
+}
+
+// Match returns the rule that decides the owners of path: the last matching one.
+func (r Ruleset) Match(path string) (Rule, bool) {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(r.Rules) - 1; i >= 0; i-- {
+		if r.Rules[i].re.MatchString(path) {
+			return r.Rules[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// Owners returns the owners of path, nil if nobody owns it.
+func (r Ruleset) Owners(path string) []string {
+	rule, ok := r.Match(path)
+	if !ok || len(rule.Owners) == 0 {
+		return nil
+	}
+	return rule.Owners
+}
+
+// OwnersOf returns the owners of any of the paths, those owning more paths first.
+func (r Ruleset) OwnersOf(paths []string) []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, path := range paths {
+		for _, owner := range r.Owners(path) {
+			if counts[owner] == 0 {
+				order = append(order, owner)
+			}
+			counts[owner]++
+		}
+	}
+	slices.SortStableFunc(order, func(a, b string) int {
+		return counts[b] - counts[a]
+	})
+	return order
+}
+
+// compile turns a gitignore-style pattern into a regexp over slash separated paths.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+	}
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*")
+	case strings.HasSuffix(p, "/*"):
+		// GitHub: `docs/*` owns the files of docs, not of its subdirectories
+	default:
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// SplitOwners returns the user logins and team slugs of the owners, email owners are skipped.
+func SplitOwners(owners []string) (users []string, teams []string) {
+	for _, owner := range owners {
+		name, ok := strings.CutPrefix(owner, "@")
+		if !ok {
+			continue
+		}
+		if _, team, isTeam := strings.Cut(name, "/"); isTeam {
+			teams = append(teams, team)
+			continue
+		}
+		users = append(users, name)
+	}
+	return users, teams
+}
@@ -0,0 +1,98 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The file follows the example from the GitHub CODEOWNERS documentation.
+const example = `
+# global owners
+*       @global-owner1 @global-owner2
+
+*.js    @js-owner #This is an inline comment.
+*.go    docs@example.com
+*.txt   @octo-org/octocats
+/build/logs/ @doctocat
+docs/*  docs@example.com
+apps/   @octocat
+/docs/  @doctocat
+/scripts/ @doctocat @octocat
+**/logs @octocat
+/apps/ @octocat
+/apps/github
+`
+
+func TestOwnersLastMatchWins(t *testing.T) {
+	rules, err := Parse(example)
+	require.NoError(t, err)
+
+	cases := map[string][]string{
+		"README.md":                       {"@global-owner1", "@global-owner2"},
+		"src/app.js":                      {"@js-owner"},
+		"main.go":                         {"docs@example.com"},
+		"notes/todo.txt":                  {"@octo-org/octocats"},
+		"build/logs/today.log":            {"@octocat"},
+		"docs/getting-started.md":         {"@doctocat"},
+		"docs/build-app/troubleshoot.md":  {"@doctocat"},
+		"nested/apps/main.rb":             {"@octocat"},
+		"scripts/deploy.sh":               {"@doctocat", "@octocat"},
+		"deeply/nested/logs/app.log":      {"@octocat"},
+		"apps/github/index.rb":            nil,
+		"apps/gitlab/index.rb":            {"@octocat"},
+		"/scripts/with-leading-slash.txt": {"@doctocat", "@octocat"},
+	}
+	for path, owners := range cases {
+		assert.Equal(t, owners, rules.Owners(path), path)
+	}
+}
+
+func TestPatternSemantics(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/sub/a.md", false},
+		{"docs", "docs/sub/a.md", true},
+		{"docs", "src/docs/a.md", true},
+		{"/docs", "src/docs/a.md", false},
+		{"src/*.go", "src/main.go", true},
+		{"src/*.go", "lib/src/main.go", false},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"src/**/*.go", "src/main.go", true},
+		{"**/test", "a/test/b.go", true},
+		{"ap?/", "app/x", true},
+		{"ap?/", "app", false},
+		{"*", "any/path.txt", true},
+		{`\#notes`, "#notes", true},
+	}
+	for _, c := range cases {
+		rules, err := Parse(c.pattern + " @owner")
+		require.NoError(t, err)
+		_, ok := rules.Match(c.path)
+		assert.Equal(t, c.match, ok, "%s ~ %s", c.pattern, c.path)
+	}
+}
+
+func TestParseRejectsUnsupportedSyntax(t *testing.T) {
+	for _, content := range []string{"!docs @owner", "[a-z].go @owner", "/ @owner"} {
+		_, err := Parse(content)
+		assert.ErrorIs(t, err, ErrInvalidPattern, content)
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	rules, err := Parse("*.go @gopher\n/api/ @api-team @gopher\n*.md @writer")
+	require.NoError(t, err)
+
+	owners := rules.OwnersOf([]string{"README.md", "api/server.go", "api/types.go", "main.go"})
+	assert.Equal(t, []string{"@gopher", "@api-team", "@writer"}, owners)
+
+	users, teams := SplitOwners([]string{"@gopher", "@acme/backend", "docs@example.com"})
+	assert.Equal(t, []string{"gopher"}, users)
+	assert.Equal(t, []string{"backend"}, teams)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -10,6 +11,12 @@ import (
 type Config struct {
 	RestConfig     `yaml:"rest" env-required:"true"`
 	PostgresConfig `yaml:"postgres" env-required:"true"`
+	SelectorConfig `yaml:"selector"`
+	WebhookConfig  `yaml:"webhook"`
+	GitHubConfig   `yaml:"github"`
+	CodeHostConfig `yaml:"code_host"`
+	ReminderConfig `yaml:"reminder"`
+	HealthConfig   `yaml:"health"`
 }
 
 type RestConfig struct {
@@ -25,6 +32,46 @@ type PostgresConfig struct {
 	Database string `yaml:"database" env-required:"true" env:"POSTGRES_DB"`
 }
 
+type SelectorConfig struct {
+	Strategy string `yaml:"strategy" env:"REVIEWER_STRATEGY" env-default:"least_loaded"`
+	Seed     int64  `yaml:"seed" env:"REVIEWER_SEED"`
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOK_BATCH_SIZE" env-default:"20"`
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" env-default:"5s"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" env:"WEBHOOK_BASE_BACKOFF" env-default:"2s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" env-default:"10m"`
+}
+
+type GitHubConfig struct {
+	WebhookSecret string `yaml:"webhook_secret" env:"GITHUB_WEBHOOK_SECRET"`
+}
+
+type CodeHostConfig struct {
+	BaseURL      string        `yaml:"base_url" env:"CODE_HOST_URL" env-default:"https://api.github.com"`
+	Token        string        `yaml:"token" env:"CODE_HOST_TOKEN"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"CODE_HOST_POLL_INTERVAL" env-default:"2s"`
+	BatchSize    int           `yaml:"batch_size" env:"CODE_HOST_BATCH_SIZE" env-default:"20"`
+	Timeout      time.Duration `yaml:"timeout" env:"CODE_HOST_TIMEOUT" env-default:"10s"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"CODE_HOST_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" env:"CODE_HOST_BASE_BACKOFF" env-default:"5s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"CODE_HOST_MAX_BACKOFF" env-default:"30m"`
+}
+
+type ReminderConfig struct {
+	PollInterval  time.Duration `yaml:"poll_interval" env:"REMINDER_POLL_INTERVAL" env-default:"1m"`
+	BatchSize     int           `yaml:"batch_size" env:"REMINDER_BATCH_SIZE" env-default:"50"`
+	RemindAfter   time.Duration `yaml:"remind_after" env:"REMINDER_REMIND_AFTER" env-default:"24h"`
+	EscalateAfter time.Duration `yaml:"escalate_after" env:"REMINDER_ESCALATE_AFTER" env-default:"72h"`
+}
+
+type HealthConfig struct {
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT" env-default:"2s"`
+}
+
 func MustLoadConfig() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (h *handler) GetUsersAbsencesList(w http.ResponseWriter, r *http.Request, params api.GetUsersAbsencesListParams) {
+	includePast := params.IncludePast != nil && *params.IncludePast
+	absences, err := h.userUseCase.ListAbsences(r.Context(), params.UserId, includePast)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":  params.UserId,
+		"absences": convert.Many(convertAbsenceToApi, absences),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostUsersAbsencesAdd(w http.ResponseWriter, r *http.Request) {
+	var req api.PostUsersAbsencesAddJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	absence := model.Absence{
+		UserID:   req.UserId,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+	if req.Reason != nil {
+		absence.Reason = *req.Reason
+	}
+	absence, err := h.userUseCase.AddAbsence(r.Context(), absence)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeAbsence(w, http.StatusCreated, absence)
+}
+
+func (h *handler) PostUsersAbsencesUpdate(w http.ResponseWriter, r *http.Request) {
+	var req api.PostUsersAbsencesUpdateJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	absence := model.Absence{
+		ID:       req.AbsenceId,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+	if req.Reason != nil {
+		absence.Reason = *req.Reason
+	}
+	absence, err := h.userUseCase.UpdateAbsence(r.Context(), absence)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeAbsence(w, http.StatusOK, absence)
+}
+
+func (h *handler) PostUsersAbsencesDelete(w http.ResponseWriter, r *http.Request) {
+	var req api.PostUsersAbsencesDeleteJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	if err := h.userUseCase.DeleteAbsence(r.Context(), req.AbsenceId); err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"absence_id": req.AbsenceId,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func writeAbsence(w http.ResponseWriter, status int, absence model.Absence) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"absence": convertAbsenceToApi(absence),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertAbsenceToApi(absence model.Absence) api.UserAbsence {
+	res := api.UserAbsence{
+		AbsenceId: absence.ID,
+		UserId:    absence.UserID,
+		StartsAt:  absence.StartsAt,
+		EndsAt:    absence.EndsAt,
+		Reason:    absence.Reason,
+	}
+	if !absence.CreatedAt.IsZero() {
+		res.CreatedAt = &absence.CreatedAt
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (h *handler) GetPullRequestSync(w http.ResponseWriter, r *http.Request, params api.GetPullRequestSyncParams) {
+	sync, err := h.codeHostUseCase.GetSync(r.Context(), params.PullRequestId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sync": convertSyncToApi(sync),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertSyncToApi(sync model.CodeHostSync) api.CodeHostSync {
+	res := api.CodeHostSync{
+		PullRequestId:   sync.PullRequestID,
+		Status:          api.CodeHostSyncStatus(sync.Status),
+		Attempts:        sync.Attempts,
+		SyncedReviewers: sync.SyncedReviewers,
+		LastError:       optionalString(sync.LastError),
+		NextAttemptAt:   sync.NextAttemptAt,
+		UpdatedAt:       sync.UpdatedAt,
+	}
+	if res.SyncedReviewers == nil {
+		res.SyncedReviewers = []string{}
+	}
+	if !sync.SyncedAt.IsZero() {
+		syncedAt := sync.SyncedAt
+		res.SyncedAt = &syncedAt
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+// maxGitHubPayload matches the limit GitHub applies to webhook payloads.
+const maxGitHubPayload = 25 << 20
+
+func (h *handler) PostIntegrationsGithubWebhook(w http.ResponseWriter, r *http.Request, params api.PostIntegrationsGithubWebhookParams) {
+	// the signature covers the raw body, so it is read as is
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGitHubPayload))
+	if err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.INVALIDREQUEST, "invalid request body")
+		return
+	}
+
+	delivery := model.GitHubDelivery{
+		ID:    params.XGitHubDelivery,
+		Event: params.XGitHubEvent,
+		Body:  body,
+	}
+	if params.XHubSignature256 != nil {
+		delivery.Signature = *params.XHubSignature256
+	}
+	result, err := h.githubUseCase.HandleWebhook(r.Context(), delivery)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": api.GitHubDeliveryResult{
+			DeliveryId:    result.DeliveryID,
+			Status:        api.GitHubDeliveryResultStatus(result.Status),
+			Action:        optionalString(result.Action),
+			PullRequestId: optionalString(result.PullRequestID),
+			Reason:        optionalString(result.Reason),
+		},
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostIntegrationsGithubUsersSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostIntegrationsGithubUsersSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	user, err := h.githubUseCase.SetUser(r.Context(), model.GitHubUser{
+		Login:  req.Login,
+		UserID: req.UserId,
+	})
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"user": convertGitHubUserToApi(user),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetIntegrationsGithubUsersList(w http.ResponseWriter, r *http.Request) {
+	users, err := h.githubUseCase.ListUsers(r.Context())
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"users": convert.Many(convertGitHubUserToApi, users),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertGitHubUserToApi(user model.GitHubUser) api.GitHubUser {
+	return api.GitHubUser{
+		Login:  user.Login,
+		UserId: user.UserID,
+	}
+}
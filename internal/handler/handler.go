@@ -6,10 +6,14 @@ import (
 	"net/http"
 
 	"github.com/doverlof/avito_help/api"
+	codeHostUseCase "github.com/doverlof/avito_help/internal/usecase/codehost"
+	githubUseCase "github.com/doverlof/avito_help/internal/usecase/github"
+	healthUseCase "github.com/doverlof/avito_help/internal/usecase/health"
 	pullRequestUseCase "github.com/doverlof/avito_help/internal/usecase/pull-request"
 	statsUseCase "github.com/doverlof/avito_help/internal/usecase/stats"
 	teamUseCase "github.com/doverlof/avito_help/internal/usecase/team"
 	userUseCase "github.com/doverlof/avito_help/internal/usecase/user"
+	webhookUseCase "github.com/doverlof/avito_help/internal/usecase/webhook"
 )
 
 type handler struct {
@@ -17,6 +21,10 @@ type handler struct {
 	userUseCase        userUseCase.UseCase
 	statsUseCase       statsUseCase.UseCase
 	pullRequestUseCase pullRequestUseCase.UseCase
+	webhookUseCase     webhookUseCase.UseCase
+	githubUseCase      githubUseCase.UseCase
+	codeHostUseCase    codeHostUseCase.UseCase
+	healthUseCase      healthUseCase.UseCase
 }
 
 func New(
@@ -24,12 +32,20 @@ func New(
 	userUseCase userUseCase.UseCase,
 	statsUseCase statsUseCase.UseCase,
 	pullRequestUseCase pullRequestUseCase.UseCase,
+	webhookUseCase webhookUseCase.UseCase,
+	githubUseCase githubUseCase.UseCase,
+	codeHostUseCase codeHostUseCase.UseCase,
+	healthUseCase healthUseCase.UseCase,
 ) api.ServerInterface {
 	return &handler{
 		teamUseCase:        teamUseCase,
 		userUseCase:        userUseCase,
 		statsUseCase:       statsUseCase,
 		pullRequestUseCase: pullRequestUseCase,
+		webhookUseCase:     webhookUseCase,
+		githubUseCase:      githubUseCase,
+		codeHostUseCase:    codeHostUseCase,
+		healthUseCase:      healthUseCase,
 	}
 }
 
@@ -43,7 +59,16 @@ func mapErrorToAPI(err error) (int, api.ErrorResponseErrorCode, string) {
 		return http.StatusNotFound, api.NOTFOUND, "author or team not found"
 
 	case errors.Is(err, pullRequestUseCase.ErrPRAlreadyMerged):
-		return http.StatusConflict, api.PRMERGED, "cannot reassign on merged PR"
+		return http.StatusConflict, api.PRMERGED, "pull request already merged"
+
+	case errors.Is(err, pullRequestUseCase.ErrNotEnoughApprovals):
+		return http.StatusConflict, api.NOTENOUGHAPPROVALS, "not enough approvals to merge"
+
+	case errors.Is(err, pullRequestUseCase.ErrInvalidVerdict):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, pullRequestUseCase.ErrPRClosed):
+		return http.StatusConflict, api.PRCLOSED, "pull request is closed"
 
 	case errors.Is(err, pullRequestUseCase.ErrDontHaveReviewers):
 		return http.StatusConflict, api.NOCANDIDATE, "no active replacement candidate in team"
@@ -51,12 +76,93 @@ func mapErrorToAPI(err error) (int, api.ErrorResponseErrorCode, string) {
 	case errors.Is(err, pullRequestUseCase.ErrNotAssigned):
 		return http.StatusConflict, api.NOTASSIGNED, "reviewer is not assigned to this PR"
 
+	case errors.Is(err, pullRequestUseCase.ErrNotEnoughReviewers):
+		return http.StatusConflict, api.NOTENOUGHREVIEWERS, "not enough reviewer candidates in team"
+
+	case errors.Is(err, pullRequestUseCase.ErrAuthorInactive):
+		return http.StatusConflict, api.AUTHORINACTIVE, "inactive author can't open pull requests"
+
+	case errors.Is(err, pullRequestUseCase.ErrConcurrentChange):
+		return http.StatusConflict, api.INVALIDREQUEST, "reviewers were changed concurrently, try again"
+
+	case errors.Is(err, teamUseCase.ErrInvalidSettings):
+		return http.StatusBadRequest, api.INVALIDSETTINGS, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrNoMembersSelected):
+		return http.StatusBadRequest, api.INVALIDREQUEST, "either user_ids or all must be set"
+
+	case errors.Is(err, teamUseCase.ErrInvalidFallbacks):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrDuplicateMember):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrMemberDeactivation):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrInvalidUpdate), errors.Is(err, teamUseCase.ErrInvalidDelete):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrInvalidCodeOwners):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, teamUseCase.ErrCodeOwnersNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "CODEOWNERS file not found"
+
+	case errors.Is(err, teamUseCase.ErrTeamHasOpenReviews):
+		return http.StatusConflict, api.TEAMHASOPENREVIEWS, "team members still have open reviews"
+
+	case errors.Is(err, teamUseCase.ErrMemberNotFound):
+		return http.StatusNotFound, api.NOTFOUND, err.Error()
+
 	case errors.Is(err, teamUseCase.ErrTeamExists):
 		return http.StatusConflict, api.TEAMEXISTS, "team already exists"
 
 	case errors.Is(err, teamUseCase.ErrTeamNotFound):
 		return http.StatusNotFound, api.NOTFOUND, "team not found"
 
+	case errors.Is(err, webhookUseCase.ErrInvalidSubscription), errors.Is(err, webhookUseCase.ErrInvalidFilter):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, webhookUseCase.ErrSubscriptionNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "webhook subscription not found"
+
+	case errors.Is(err, githubUseCase.ErrInvalidSignature):
+		return http.StatusUnauthorized, api.INVALIDSIGNATURE, "invalid webhook signature"
+
+	case errors.Is(err, githubUseCase.ErrMalformedPayload), errors.Is(err, githubUseCase.ErrInvalidUser):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, githubUseCase.ErrUserNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "user not found"
+
+	case errors.Is(err, githubUseCase.ErrUserLinked):
+		return http.StatusConflict, api.INVALIDREQUEST, "user is linked to another github login"
+
+	case errors.Is(err, userUseCase.ErrInvalidSkills):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, userUseCase.ErrInvalidAbsence):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, userUseCase.ErrAbsenceNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "absence not found"
+
+	case errors.Is(err, userUseCase.ErrInvalidSchedule):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, userUseCase.ErrScheduleNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "working hours are not set"
+
+	case errors.Is(err, userUseCase.ErrUserNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "user not found"
+
+	case errors.Is(err, statsUseCase.ErrInvalidWindow):
+		return http.StatusBadRequest, api.INVALIDREQUEST, err.Error()
+
+	case errors.Is(err, codeHostUseCase.ErrSyncNotFound):
+		return http.StatusNotFound, api.NOTFOUND, "reviewers of the pull request were not synced yet"
+
 	default:
 		return http.StatusInternalServerError, api.NOTFOUND, "internal server error"
 	}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (h *handler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "ok",
+	}); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func (h *handler) GetReady(w http.ResponseWriter, r *http.Request) {
+	readiness := h.healthUseCase.Ready(r.Context())
+
+	res := api.Readiness{
+		Status: api.ReadinessStatusOk,
+		Checks: convert.Many(convertHealthCheckToApi, readiness.Checks),
+	}
+	status := http.StatusOK
+	if !readiness.Ready {
+		res.Status = api.ReadinessStatusFail
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func convertHealthCheckToApi(check model.HealthCheck) api.HealthCheck {
+	res := api.HealthCheck{
+		Name:    check.Name,
+		Status:  api.HealthCheckStatus(check.Status),
+		Message: optionalString(check.Message),
+	}
+	if !check.LastRunAt.IsZero() {
+		res.LastRunAt = &check.LastRunAt
+	}
+	return res
+}
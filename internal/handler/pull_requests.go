@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
 	"github.com/doverlof/avito_help/internal/model"
 )
 
@@ -16,7 +17,7 @@ func (h *handler) PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
 		return
 	}
-	err := h.pullRequestUseCase.Create(r.Context(), convertFromApi(req))
+	pullRequest, err := h.pullRequestUseCase.Create(r.Context(), convertFromApi(req))
 	if err != nil {
 		fmt.Println(err)
 		status, code, msg := mapErrorToAPI(err)
@@ -24,10 +25,10 @@ func (h *handler) PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	if err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"team": req,
+		"pr": convertPullRequestToApi(pullRequest),
 	}); err != nil {
 		fmt.Println(err)
 		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
@@ -36,11 +37,21 @@ func (h *handler) PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
 }
 
 func convertFromApi(create api.PostPullRequestCreateJSONRequestBody) model.CreatePullRequest {
-	return model.CreatePullRequest{
+	res := model.CreatePullRequest{
 		AuthorID:        create.AuthorId,
 		PullRequestID:   create.PullRequestId,
 		PullRequestName: create.PullRequestName,
 	}
+	if create.Repository != nil {
+		res.Repository = *create.Repository
+	}
+	if create.ChangedFiles != nil {
+		res.ChangedFiles = *create.ChangedFiles
+	}
+	if create.Labels != nil {
+		res.Labels = *create.Labels
+	}
+	return res
 }
 
 func (h *handler) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
@@ -51,7 +62,8 @@ func (h *handler) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pullRequest, err := h.pullRequestUseCase.Merge(r.Context(), req.PullRequestId)
+	force := req.Force != nil && *req.Force
+	pullRequest, err := h.pullRequestUseCase.Merge(r.Context(), req.PullRequestId, force)
 	if err != nil {
 		fmt.Println(err)
 		status, code, msg := mapErrorToAPI(err)
@@ -62,14 +74,42 @@ func (h *handler) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"pr": api.PullRequest{
-			AuthorId:          pullRequest.AuthorID,
-			AssignedReviewers: pullRequest.ReviewerIDs,
-			MergedAt:          &pullRequest.MergedAt,
-			PullRequestId:     pullRequest.PullRequestID,
-			PullRequestName:   pullRequest.PullRequestName,
-			Status:            convertPRStatus(pullRequest.Status),
-		},
+		"pr": convertPullRequestToApi(pullRequest),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostPullRequestReview(w http.ResponseWriter, r *http.Request) {
+	var req api.PostPullRequestReviewJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	review := model.Review{
+		PullRequestID: req.PullRequestId,
+		ReviewerID:    req.ReviewerId,
+		Verdict:       model.ReviewVerdict(req.Verdict),
+	}
+	if req.Comment != nil {
+		review.Comment = *req.Comment
+	}
+	pullRequest, err := h.pullRequestUseCase.Review(r.Context(), review)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"pr": convertPullRequestToApi(pullRequest),
 	}); err != nil {
 		fmt.Println(err)
 		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
@@ -77,10 +117,120 @@ func (h *handler) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) PostPullRequestClose(w http.ResponseWriter, r *http.Request) {
+	var req api.PostPullRequestCloseJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	pullRequest, err := h.pullRequestUseCase.Close(r.Context(), req.PullRequestId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"pr": convertPullRequestToApi(pullRequest),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostPullRequestReopen(w http.ResponseWriter, r *http.Request) {
+	var req api.PostPullRequestReopenJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	pullRequest, reassignments, err := h.pullRequestUseCase.Reopen(r.Context(), req.PullRequestId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"pr":            convertPullRequestToApi(pullRequest),
+		"reassignments": convert.Many(convertReassignmentToApi, reassignments),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertPullRequestToApi(pullRequest model.PullRequest) api.PullRequest {
+	res := api.PullRequest{
+		AuthorId:          pullRequest.AuthorID,
+		AssignedReviewers: pullRequest.ReviewerIDs,
+		PullRequestId:     pullRequest.PullRequestID,
+		PullRequestName:   pullRequest.PullRequestName,
+		Status:            convertPRStatus(pullRequest.Status),
+		NeedsReviewer:     &pullRequest.NeedsReviewer,
+	}
+	if !pullRequest.CreatedAt.IsZero() {
+		res.CreatedAt = &pullRequest.CreatedAt
+	}
+	if !pullRequest.MergedAt.IsZero() {
+		res.MergedAt = &pullRequest.MergedAt
+	}
+	if !pullRequest.ClosedAt.IsZero() {
+		res.ClosedAt = &pullRequest.ClosedAt
+	}
+	if len(pullRequest.Labels) > 0 {
+		res.Labels = &pullRequest.Labels
+	}
+	reviewers := convert.Many(convertReviewerToApi, pullRequest.Reviewers)
+	res.Reviewers = &reviewers
+	return res
+}
+
+func convertReviewerToApi(reviewer model.Reviewer) api.ReviewerAssignment {
+	res := api.ReviewerAssignment{
+		UserId:      reviewer.ID,
+		IsFallback:  reviewer.IsFallback,
+		IsCodeOwner: reviewer.IsCodeOwner,
+		IsOverdue:   &reviewer.IsOverdue,
+	}
+	if !reviewer.DueAt.IsZero() {
+		res.DueAt = &reviewer.DueAt
+	}
+	if reviewer.TeamName != "" {
+		res.TeamName = &reviewer.TeamName
+	}
+	if !reviewer.AssignedAt.IsZero() {
+		res.AssignedAt = &reviewer.AssignedAt
+	}
+	if len(reviewer.MatchedTags) > 0 {
+		res.MatchedTags = &reviewer.MatchedTags
+	}
+	if reviewer.Verdict != "" {
+		verdict := api.ReviewVerdict(reviewer.Verdict)
+		res.Verdict = &verdict
+		res.VerdictAt = &reviewer.VerdictAt
+	}
+	return res
+}
+
 func convertPRStatus(status model.PullRequestStatus) api.PullRequestStatus {
 	switch status {
 	case model.StatusOpen:
 		return api.PullRequestStatusOPEN
+	case model.StatusClosed:
+		return api.PullRequestStatusCLOSED
 	default:
 		return api.PullRequestStatusMERGED
 	}
@@ -104,14 +254,7 @@ func (h *handler) PostPullRequestReassign(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"pr": api.PullRequest{
-			AuthorId:          pullRequest.AuthorID,
-			AssignedReviewers: pullRequest.ReviewerIDs,
-			MergedAt:          &pullRequest.MergedAt,
-			PullRequestId:     pullRequest.PullRequestID,
-			PullRequestName:   pullRequest.PullRequestName,
-			Status:            convertPRStatus(pullRequest.Status),
-		},
+		"pr":          convertPullRequestToApi(pullRequest),
 		"replaced_by": newRewieverID,
 	}); err != nil {
 		fmt.Println(err)
@@ -119,3 +262,56 @@ func (h *handler) PostPullRequestReassign(w http.ResponseWriter, r *http.Request
 		return
 	}
 }
+
+func (h *handler) GetPullRequestHistory(w http.ResponseWriter, r *http.Request, params api.GetPullRequestHistoryParams) {
+	events, err := h.pullRequestUseCase.GetHistory(r.Context(), params.PullRequestId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"pull_request_id": params.PullRequestId,
+		"events":          convert.Many(convertEventToApi, events),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertEventToApi(event model.PullRequestEvent) api.PullRequestEvent {
+	res := api.PullRequestEvent{
+		EventId:       event.ID,
+		EventType:     api.PullRequestEventEventType(event.Type),
+		CreatedAt:     event.CreatedAt,
+		Actor:         optionalString(event.Actor),
+		ReviewerId:    optionalString(event.ReviewerID),
+		OldReviewerId: optionalString(event.OldReviewerID),
+		Reason:        optionalString(event.Reason),
+	}
+	if event.FromStatus != "" {
+		from := api.PullRequestEventFromStatus(event.FromStatus)
+		res.FromStatus = &from
+	}
+	if event.ToStatus != "" {
+		to := api.PullRequestEventToStatus(event.ToStatus)
+		res.ToStatus = &to
+	}
+	if event.Verdict != "" {
+		verdict := api.ReviewVerdict(event.Verdict)
+		res.Verdict = &verdict
+	}
+	return res
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (h *handler) GetReviewsOverdue(w http.ResponseWriter, r *http.Request, params api.GetReviewsOverdueParams) {
+	var filter model.OverdueFilter
+	if params.TeamName != nil {
+		filter.TeamName = *params.TeamName
+	}
+	if params.UserId != nil {
+		filter.UserID = *params.UserId
+	}
+	reviews, err := h.pullRequestUseCase.GetOverdue(r.Context(), filter)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"reviews": convert.Many(convertOverdueReviewToApi, reviews),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertOverdueReviewToApi(review model.OverdueReview) api.OverdueReview {
+	res := api.OverdueReview{
+		PullRequestId:   review.PullRequestID,
+		PullRequestName: review.PullRequestName,
+		AuthorId:        review.AuthorID,
+		ReviewerId:      review.ReviewerID,
+		AssignedAt:      review.AssignedAt,
+		DueAt:           review.DueAt,
+	}
+	if review.ReviewerTeam != "" {
+		res.ReviewerTeam = &review.ReviewerTeam
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/workhours"
+)
+
+func (h *handler) GetUsersScheduleGet(w http.ResponseWriter, r *http.Request, params api.GetUsersScheduleGetParams) {
+	schedule, err := h.userUseCase.GetSchedule(r.Context(), params.UserId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeSchedule(w, params.UserId, schedule)
+}
+
+func (h *handler) PostUsersScheduleSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostUsersScheduleSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+	schedule, err := convertScheduleFromApi(req.Schedule)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, api.INVALIDREQUEST, err.Error())
+		return
+	}
+
+	schedule, err = h.userUseCase.SetSchedule(r.Context(), req.UserId, schedule)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeSchedule(w, req.UserId, schedule)
+}
+
+func writeSchedule(w http.ResponseWriter, userID string, schedule model.WorkSchedule) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":  userID,
+		"schedule": convertScheduleToApi(schedule, time.Now()),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertScheduleFromApi(schedule api.WorkSchedule) (model.WorkSchedule, error) {
+	start, err := workhours.ParseClock(schedule.WorkStart)
+	if err != nil {
+		return model.WorkSchedule{}, err
+	}
+	end, err := workhours.ParseClock(schedule.WorkEnd)
+	if err != nil {
+		return model.WorkSchedule{}, err
+	}
+	weekdays := make([]time.Weekday, len(schedule.Weekdays))
+	for i, day := range schedule.Weekdays {
+		if day < 1 || day > 7 {
+			return model.WorkSchedule{}, fmt.Errorf("weekday %d is out of 1..7", day)
+		}
+		weekdays[i] = time.Weekday(day % 7)
+	}
+	return model.WorkSchedule{
+		Timezone:    schedule.Timezone,
+		StartMinute: start,
+		EndMinute:   end,
+		Weekdays:    weekdays,
+	}, nil
+}
+
+func convertScheduleToApi(schedule model.WorkSchedule, now time.Time) api.WorkSchedule {
+	weekdays := make([]int, len(schedule.Weekdays))
+	for i, day := range schedule.Weekdays {
+		weekdays[i] = int(day)
+		if day == time.Sunday {
+			weekdays[i] = 7
+		}
+	}
+	next := workhours.Next(schedule, now)
+	isWorkingNow := next.Equal(now)
+	return api.WorkSchedule{
+		Timezone:      schedule.Timezone,
+		WorkStart:     workhours.FormatClock(schedule.StartMinute),
+		WorkEnd:       workhours.FormatClock(schedule.EndMinute),
+		Weekdays:      weekdays,
+		IsWorkingNow:  &isWorkingNow,
+		NextWorkingAt: &next,
+	}
+}
@@ -7,16 +7,19 @@ import (
 	"github.com/doverlof/avito_help/internal/convert"
 	"github.com/doverlof/avito_help/internal/model"
 	"net/http"
+	"time"
 )
 
-func (h *handler) GetStatsUsers(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.statsUseCase.GetUserStatistics(r.Context())
+func (h *handler) GetStatsUsers(w http.ResponseWriter, r *http.Request, params api.GetStatsUsersParams) {
+	stats, err := h.statsUseCase.GetUserStatistics(r.Context(), statsFilter(params.From, params.To, params.TeamName))
 	if err != nil {
 		fmt.Println(err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err = json.NewEncoder(w).Encode(map[string]interface{}{
 		"statistics": convert.Many(convertUserStatsToApi, stats),
@@ -27,6 +30,53 @@ func (h *handler) GetStatsUsers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) GetStatsTeams(w http.ResponseWriter, r *http.Request, params api.GetStatsTeamsParams) {
+	stats, err := h.statsUseCase.GetTeamStatistics(r.Context(), statsFilter(params.From, params.To, params.TeamName))
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"statistics": convert.Many(convertTeamStatsToApi, stats),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func statsFilter(from, to *time.Time, teamName *string) model.StatsFilter {
+	var filter model.StatsFilter
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+	if teamName != nil {
+		filter.TeamName = *teamName
+	}
+	return filter
+}
+
+func convertTeamStatsToApi(stats model.TeamStatistics) api.TeamStatistics {
+	return api.TeamStatistics{
+		TeamName:          stats.TeamName,
+		OpenedPrs:         stats.OpenedPrs,
+		MergedPrs:         stats.MergedPrs,
+		AvgReviewersPerPr: stats.AvgReviewersPerPr,
+		ActiveMembers:     stats.ActiveMembers,
+		MinOpenReviews:    stats.MinOpenReviews,
+		MaxOpenReviews:    stats.MaxOpenReviews,
+		StddevOpenReviews: stats.StddevOpenReviews,
+	}
+}
+
 func convertUserStatsToApi(stats model.UserStatistics) api.UserStatistics {
 	return api.UserStatistics{
 		UserId:   stats.UserId,
@@ -37,9 +87,146 @@ func convertUserStatsToApi(stats model.UserStatistics) api.UserStatistics {
 		TotalReviewAssignments:  stats.TotalReviewAssignments,
 		OpenReviewAssignments:   stats.OpenReviewAssignments,
 		MergedReviewAssignments: stats.MergedReviewAssignments,
+		ClosedReviewAssignments: stats.ClosedReviewAssignments,
 
 		TotalAuthoredPrs:  stats.TotalAuthoredPrs,
 		OpenAuthoredPrs:   stats.OpenAuthoredPrs,
 		MergedAuthoredPrs: stats.MergedAuthoredPrs,
+		ClosedAuthoredPrs: stats.ClosedAuthoredPrs,
+	}
+}
+
+func (h *handler) GetStatsSla(w http.ResponseWriter, r *http.Request, params api.GetStatsSlaParams) {
+	var from, to time.Time
+	var teamName string
+	if params.From != nil {
+		from = *params.From
+	}
+	if params.To != nil {
+		to = *params.To
+	}
+	if params.TeamName != nil {
+		teamName = *params.TeamName
+	}
+	compliance, err := h.statsUseCase.GetSLACompliance(r.Context(), from, to, teamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sla": convertSLAComplianceToApi(compliance),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertSLAComplianceToApi(compliance model.SLACompliance) api.SLACompliance {
+	return api.SLACompliance{
+		From:              compliance.From,
+		To:                compliance.To,
+		Total:             compliance.Total,
+		WithinSla:         compliance.WithinSLA,
+		CompliancePercent: model.CompliancePercent(compliance.WithinSLA, compliance.Total),
+		Teams:             convert.Many(convertTeamSLAComplianceToApi, compliance.Teams),
+	}
+}
+
+func convertTeamSLAComplianceToApi(compliance model.TeamSLACompliance) api.TeamSLACompliance {
+	return api.TeamSLACompliance{
+		TeamName:          compliance.TeamName,
+		Total:             compliance.Total,
+		WithinSla:         compliance.WithinSLA,
+		CompliancePercent: model.CompliancePercent(compliance.WithinSLA, compliance.Total),
+	}
+}
+
+func (h *handler) GetStatsLatency(w http.ResponseWriter, r *http.Request, params api.GetStatsLatencyParams) {
+	filter := statsFilter(params.From, params.To, params.TeamName)
+	report, err := h.statsUseCase.GetLatency(r.Context(), filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"latency": convertLatencyReportToApi(report),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertLatencyReportToApi(report model.LatencyReport) api.LatencyReport {
+	return api.LatencyReport{
+		From:      report.From,
+		To:        report.To,
+		Overall:   convertLatencyToApi(report.Overall),
+		Teams:     convert.Many(convertTeamLatencyToApi, report.Teams),
+		Reviewers: convert.Many(convertReviewerLatencyToApi, report.Reviewers),
+		Weekly:    convert.Many(convertWeeklyLatencyToApi, report.Weekly),
+	}
+}
+
+func convertTeamLatencyToApi(team model.TeamLatency) api.TeamLatency {
+	latency := convertLatencyToApi(team.Latency)
+	return api.TeamLatency{
+		TeamName:          team.TeamName,
+		MergedPrs:         latency.MergedPrs,
+		Assignments:       latency.Assignments,
+		TimeToMerge:       latency.TimeToMerge,
+		AssignmentToMerge: latency.AssignmentToMerge,
+	}
+}
+
+func convertReviewerLatencyToApi(reviewer model.ReviewerLatency) api.ReviewerLatency {
+	latency := convertLatencyToApi(reviewer.Latency)
+	return api.ReviewerLatency{
+		ReviewerId:        reviewer.ReviewerID,
+		TeamName:          reviewer.TeamName,
+		MergedPrs:         latency.MergedPrs,
+		Assignments:       latency.Assignments,
+		TimeToMerge:       latency.TimeToMerge,
+		AssignmentToMerge: latency.AssignmentToMerge,
+	}
+}
+
+func convertWeeklyLatencyToApi(week model.WeeklyLatency) api.WeeklyLatency {
+	latency := convertLatencyToApi(week.Latency)
+	return api.WeeklyLatency{
+		WeekStart:         week.WeekStart,
+		MergedPrs:         latency.MergedPrs,
+		Assignments:       latency.Assignments,
+		TimeToMerge:       latency.TimeToMerge,
+		AssignmentToMerge: latency.AssignmentToMerge,
+	}
+}
+
+func convertLatencyToApi(latency model.Latency) api.Latency {
+	return api.Latency{
+		MergedPrs:         latency.MergedPrs,
+		Assignments:       latency.Assignments,
+		TimeToMerge:       convertPercentilesToApi(latency.TimeToMerge),
+		AssignmentToMerge: convertPercentilesToApi(latency.AssignmentToMerge),
+	}
+}
+
+func convertPercentilesToApi(percentiles *model.Percentiles) *api.LatencyPercentiles {
+	if percentiles == nil {
+		return nil
+	}
+	return &api.LatencyPercentiles{
+		P50: percentiles.P50.Seconds(),
+		P90: percentiles.P90.Seconds(),
+		P99: percentiles.P99.Seconds(),
 	}
 }
@@ -18,7 +18,7 @@ func (h *handler) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.teamUseCase.Add(r.Context(), model.Team{
+	result, err := h.teamUseCase.Add(r.Context(), model.Team{
 		Name:    req.TeamName,
 		Members: convert.Many(convertMemberFromApi, req.Members),
 	})
@@ -29,15 +29,30 @@ func (h *handler) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
-	if err = json.NewEncoder(w).Encode(req); err != nil {
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": api.Team{
+			TeamName: result.Team.Name,
+			Members:  convert.Many(convertMemberFromModel, result.Team.Members),
+		},
+		"created": result.Created,
+		"updated": result.Updated,
+		"moved":   convert.Many(convertMovedMemberToApi, result.Moved),
+	}); err != nil {
 		fmt.Println(err)
 		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
 		return
 	}
 }
 
+func convertMovedMemberToApi(m model.MovedMember) api.MovedMember {
+	return api.MovedMember{
+		UserId:   m.UserID,
+		FromTeam: m.FromTeam,
+	}
+}
+
 func convertMemberFromApi(apiMember api.TeamMember) model.Member {
 	return model.Member{
 		ID:       apiMember.UserId,
@@ -67,13 +82,358 @@ func convertTeam(team model.Team) api.Team {
 	return api.Team{
 		TeamName: team.Name,
 		Members:  convert.Many(convertMemberFromModel, team.Members),
+		Rotation: &team.Rotation,
 	}
 }
 
 func convertMemberFromModel(m model.Member) api.TeamMember {
-	return api.TeamMember{
-		IsActive: m.IsActive,
-		Username: m.Name,
-		UserId:   m.ID,
+	isAvailable := m.Absence == nil
+	res := api.TeamMember{
+		IsActive:    m.IsActive,
+		IsAvailable: &isAvailable,
+		Username:    m.Name,
+		UserId:      m.ID,
+	}
+	if m.Absence != nil {
+		absence := convertAbsenceToApi(*m.Absence)
+		res.Absence = &absence
+	}
+	return res
+}
+
+func (h *handler) GetTeamSettingsGet(w http.ResponseWriter, r *http.Request, params api.GetTeamSettingsGetParams) {
+	settings, err := h.teamUseCase.GetSettings(r.Context(), params.TeamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(convertTeamSettingsToApi(settings)); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostTeamSettingsSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamSettingsSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	settings, err := h.teamUseCase.SetSettings(r.Context(), convertTeamSettingsFromApi(req))
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings": convertTeamSettingsToApi(settings),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertTeamSettingsFromApi(settings api.TeamSettings) model.TeamSettings {
+	res := model.TeamSettings{
+		TeamName:            settings.TeamName,
+		MinReviewers:        settings.MinReviewers,
+		MaxReviewers:        settings.MaxReviewers,
+		AllowInactiveAuthor: settings.AllowInactiveAuthor,
+	}
+	if settings.RequiredApprovals != nil {
+		res.RequiredApprovals = *settings.RequiredApprovals
+	}
+	if settings.AbsenceLookaheadHours != nil {
+		res.AbsenceLookaheadHours = *settings.AbsenceLookaheadHours
+	}
+	if settings.ReviewSlaHours != nil {
+		res.ReviewSLAHours = *settings.ReviewSlaHours
+	}
+	if settings.Strategy != nil {
+		res.Strategy = string(*settings.Strategy)
+	}
+	return res
+}
+
+func convertTeamSettingsToApi(settings model.TeamSettings) api.TeamSettings {
+	res := api.TeamSettings{
+		TeamName:              settings.TeamName,
+		MinReviewers:          settings.MinReviewers,
+		MaxReviewers:          settings.MaxReviewers,
+		AllowInactiveAuthor:   settings.AllowInactiveAuthor,
+		RequiredApprovals:     &settings.RequiredApprovals,
+		AbsenceLookaheadHours: &settings.AbsenceLookaheadHours,
+		ReviewSlaHours:        &settings.ReviewSLAHours,
+	}
+	if settings.Strategy != "" {
+		strategy := api.TeamSettingsStrategy(settings.Strategy)
+		res.Strategy = &strategy
+	}
+	return res
+}
+
+func (h *handler) PostTeamDeactivateMembers(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamDeactivateMembersJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	deactivate := model.DeactivateMembers{
+		TeamName: req.TeamName,
+		All:      req.All != nil && *req.All,
+	}
+	if req.UserIds != nil {
+		deactivate.UserIDs = *req.UserIds
+	}
+	if req.FallbackTeams != nil {
+		deactivate.FallbackTeams = *req.FallbackTeams
+	}
+
+	report, err := h.teamUseCase.DeactivateMembers(r.Context(), deactivate)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(api.DeactivationReport{
+		TeamName:        report.TeamName,
+		Deactivated:     report.Deactivated,
+		AlreadyInactive: report.AlreadyInactive,
+		Reassignments:   convert.Many(convertReassignmentToApi, report.Reassignments),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetTeamFallbacksGet(w http.ResponseWriter, r *http.Request, params api.GetTeamFallbacksGetParams) {
+	fallbacks, err := h.teamUseCase.GetFallbacks(r.Context(), params.TeamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(api.TeamFallbacks{
+		TeamName:      params.TeamName,
+		FallbackTeams: fallbacks,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostTeamFallbacksSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamFallbacksSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	fallbacks, err := h.teamUseCase.SetFallbacks(r.Context(), req.TeamName, req.FallbackTeams)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(api.TeamFallbacks{
+		TeamName:      req.TeamName,
+		FallbackTeams: fallbacks,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetTeamCodeownersGet(w http.ResponseWriter, r *http.Request, params api.GetTeamCodeownersGetParams) {
+	codeOwners, err := h.teamUseCase.GetCodeOwners(r.Context(), params.TeamName, params.Repository)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(convertCodeOwnersToApi(codeOwners)); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostTeamCodeownersSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamCodeownersSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	codeOwners, rules, err := h.teamUseCase.SetCodeOwners(r.Context(), model.CodeOwners{
+		TeamName:   req.TeamName,
+		Repository: req.Repository,
+		Content:    req.Content,
+	})
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"codeowners": convertCodeOwnersToApi(codeOwners),
+		"rules":      rules,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertCodeOwnersToApi(codeOwners model.CodeOwners) api.TeamCodeOwners {
+	return api.TeamCodeOwners{
+		TeamName:   codeOwners.TeamName,
+		Repository: codeOwners.Repository,
+		Content:    codeOwners.Content,
+		UpdatedAt:  &codeOwners.UpdatedAt,
+	}
+}
+
+func (h *handler) PostTeamUpdate(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamUpdateJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	update := model.TeamUpdate{Name: req.TeamName}
+	if req.AddMembers != nil {
+		update.Add = convert.Many(convertMemberFromApi, *req.AddMembers)
+	}
+	if req.RemoveMembers != nil {
+		update.Remove = *req.RemoveMembers
+	}
+
+	result, err := h.teamUseCase.Update(r.Context(), update)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team":    convertTeam(result.Team),
+		"created": result.Created,
+		"updated": result.Updated,
+		"moved":   convert.Many(convertMovedMemberToApi, result.Moved),
+		"removed": result.Removed,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostTeamRename(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamRenameJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	err := h.teamUseCase.Rename(r.Context(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	team, err := h.teamUseCase.Get(r.Context(), req.NewTeamName)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": convertTeam(team),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+	var req api.PostTeamDeleteJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	del := model.TeamDelete{
+		Name:   req.TeamName,
+		Policy: model.TeamDeletePolicy(req.Policy),
+	}
+	if req.MoveTo != nil {
+		del.MoveTo = *req.MoveTo
+	}
+
+	members, err := h.teamUseCase.Delete(r.Context(), del)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	response := map[string]interface{}{
+		"team_name": del.Name,
+		"policy":    del.Policy,
+		"members":   members,
+	}
+	if del.MoveTo != "" {
+		response["move_to"] = del.MoveTo
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
 	}
 }
@@ -24,7 +24,7 @@ func (h *handler) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userUseCase.SetIsActive(r.Context(), req.UserID, req.IsActive)
+	user, reassignments, err := h.userUseCase.SetIsActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
 		fmt.Println(err)
 		if errors.Is(err, userUseCase.ErrUserNotFound) {
@@ -37,7 +37,8 @@ func (h *handler) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 	if err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"user": convertUserToApi(user),
+		"user":          convertUserToApi(user),
+		"reassignments": convert.Many(convertReassignmentToApi, reassignments),
 	}); err != nil {
 		fmt.Println(err)
 		http.Error(w, "failed to write response: "+err.Error(), http.StatusInternalServerError)
@@ -46,7 +47,8 @@ func (h *handler) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params api.GetUsersGetReviewParams) {
-	prs, err := h.pullRequestUseCase.GetByReviewer(r.Context(), params.UserId)
+	includeClosed := params.IncludeClosed != nil && *params.IncludeClosed
+	prs, err := h.pullRequestUseCase.GetByReviewer(r.Context(), params.UserId, includeClosed)
 	if err != nil {
 		fmt.Println(err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -64,6 +66,64 @@ func (h *handler) GetUsersGetReview(w http.ResponseWriter, r *http.Request, para
 	}
 }
 
+func (h *handler) GetUsersSkillsGet(w http.ResponseWriter, r *http.Request, params api.GetUsersSkillsGetParams) {
+	skills, err := h.userUseCase.GetSkills(r.Context(), params.UserId)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeSkills(w, params.UserId, skills)
+}
+
+func (h *handler) PostUsersSkillsSet(w http.ResponseWriter, r *http.Request) {
+	var req api.PostUsersSkillsSetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	skills, err := h.userUseCase.SetSkills(r.Context(), req.UserId, convert.Many(convertSkillFromApi, req.Skills))
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+	writeSkills(w, req.UserId, skills)
+}
+
+func writeSkills(w http.ResponseWriter, userID string, skills []model.Skill) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		"skills":  convert.Many(convertSkillToApi, skills),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertSkillFromApi(skill api.UserSkill) model.Skill {
+	res := model.Skill{Tag: skill.Tag}
+	if skill.Level != nil {
+		res.Level = *skill.Level
+	}
+	return res
+}
+
+func convertSkillToApi(skill model.Skill) api.UserSkill {
+	res := api.UserSkill{Tag: skill.Tag}
+	if skill.Level != 0 {
+		res.Level = &skill.Level
+	}
+	return res
+}
+
 func convertPRToShort(pr model.PullRequest) api.PullRequestShort {
 	return api.PullRequestShort{
 		PullRequestId:   pr.PullRequestID,
@@ -81,3 +141,20 @@ func convertUserToApi(user model.User) api.User {
 		IsActive: user.IsActive,
 	}
 }
+
+func convertReassignmentToApi(reassignment model.Reassignment) api.ReviewReassignment {
+	res := api.ReviewReassignment{
+		PullRequestId: reassignment.PullRequestID,
+		OldReviewerId: reassignment.OldReviewerID,
+		NeedsReviewer: reassignment.NewReviewerID == "",
+		IsFallback:    &reassignment.IsFallback,
+	}
+	if reassignment.NewReviewerID != "" {
+		res.NewReviewerId = &reassignment.NewReviewerID
+		res.NewReviewerTeam = &reassignment.NewReviewerTeam
+	}
+	if len(reassignment.MatchedTags) > 0 {
+		res.MatchedTags = &reassignment.MatchedTags
+	}
+	return res
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/doverlof/avito_help/api"
+	"github.com/doverlof/avito_help/internal/convert"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (h *handler) PostWebhooksCreate(w http.ResponseWriter, r *http.Request) {
+	var req api.PostWebhooksCreateJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	subscription := model.WebhookSubscription{
+		URL:    req.Url,
+		Secret: req.Secret,
+	}
+	if req.EventTypes != nil {
+		subscription.EventTypes = convert.Many(func(eventType string) model.PullRequestEventType {
+			return model.PullRequestEventType(eventType)
+		}, *req.EventTypes)
+	}
+	subscription, err := h.webhookUseCase.Create(r.Context(), subscription)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscription": convertSubscriptionToApi(subscription),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetWebhooksList(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.webhookUseCase.List(r.Context())
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscriptions": convert.Many(convertSubscriptionToApi, subscriptions),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) PostWebhooksDelete(w http.ResponseWriter, r *http.Request) {
+	var req api.PostWebhooksDeleteJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusBadRequest, api.NOTFOUND, "invalid request body")
+		return
+	}
+
+	if err := h.webhookUseCase.Delete(r.Context(), req.SubscriptionId); err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscription_id": req.SubscriptionId,
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetWebhooksDeliveries(w http.ResponseWriter, r *http.Request, params api.GetWebhooksDeliveriesParams) {
+	var filter model.WebhookDeliveryFilter
+	if params.SubscriptionId != nil {
+		filter.SubscriptionID = *params.SubscriptionId
+	}
+	if params.Status != nil {
+		filter.Status = model.WebhookDeliveryStatus(*params.Status)
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	deliveries, err := h.webhookUseCase.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		fmt.Println(err)
+		status, code, msg := mapErrorToAPI(err)
+		writeError(w, status, code, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"deliveries": convert.Many(convertDeliveryToApi, deliveries),
+	}); err != nil {
+		fmt.Println(err)
+		writeError(w, http.StatusInternalServerError, api.NOTFOUND, "failed to write response")
+		return
+	}
+}
+
+func convertSubscriptionToApi(subscription model.WebhookSubscription) api.WebhookSubscription {
+	return api.WebhookSubscription{
+		SubscriptionId: subscription.ID,
+		Url:            subscription.URL,
+		EventTypes: convert.Many(func(eventType model.PullRequestEventType) string {
+			return string(eventType)
+		}, subscription.EventTypes),
+		IsActive:  subscription.IsActive,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func convertDeliveryToApi(delivery model.WebhookDelivery) api.WebhookDelivery {
+	res := api.WebhookDelivery{
+		DeliveryId:     delivery.ID,
+		SubscriptionId: delivery.SubscriptionID,
+		EventId:        delivery.Event.ID,
+		EventType:      string(delivery.Event.Type),
+		PullRequestId:  delivery.Event.PullRequestID,
+		Status:         api.WebhookDeliveryStatus(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastError:      optionalString(delivery.LastError),
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.LastStatusCode != 0 {
+		res.LastStatusCode = &delivery.LastStatusCode
+	}
+	if delivery.Status == model.DeliveryPending {
+		res.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if !delivery.DeliveredAt.IsZero() {
+		res.DeliveredAt = &delivery.DeliveredAt
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+// Package health tracks the state of the background workers for the readiness probe.
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateStopped  State = "stopped"
+	StateDisabled State = "disabled"
+)
+
+// Component is the state of one background worker, a nil *Component ignores every report.
+type Component struct {
+	name string
+
+	mu        sync.Mutex
+	state     State
+	lastRunAt time.Time
+	lastError string
+}
+
+// Status is a snapshot of a Component.
+type Status struct {
+	Name      string
+	State     State
+	LastRunAt time.Time
+	LastError string
+}
+
+// Started marks the worker as running until its first tick is reported.
+func (c *Component) Started() {
+	c.set(func() { c.state = StateStarting })
+}
+
+// Ran records the outcome of one tick of the worker.
+func (c *Component) Ran(err error) {
+	c.set(func() {
+		c.state = StateRunning
+		c.lastRunAt = time.Now()
+		c.lastError = ""
+		if err != nil {
+			c.lastError = err.Error()
+		}
+	})
+}
+
+// Stopped marks the worker as no longer running, a disabled one stays disabled.
+func (c *Component) Stopped() {
+	c.set(func() {
+		if c.state != StateDisabled {
+			c.state = StateStopped
+		}
+	})
+}
+
+// Disable marks a worker that is turned off by the config.
+func (c *Component) Disable() {
+	c.set(func() { c.state = StateDisabled })
+}
+
+func (c *Component) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Status{
+		Name:      c.name,
+		State:     c.state,
+		LastRunAt: c.lastRunAt,
+		LastError: c.lastError,
+	}
+}
+
+func (c *Component) set(update func()) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	update()
+}
+
+// Components is the ordered set of the background workers of the service.
+type Components struct {
+	mu    sync.Mutex
+	items []*Component
+}
+
+func NewComponents() *Components {
+	return &Components{}
+}
+
+// Add registers a worker under name, it is stopped until Started is called.
+func (c *Components) Add(name string) *Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	component := &Component{name: name, state: StateStopped}
+	c.items = append(c.items, component)
+	return component
+}
+
+func (c *Components) Statuses() []Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]Status, len(c.items))
+	for i, component := range c.items {
+		res[i] = component.Status()
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func RegisterDBStats(r *Registry, db interface{ Stats() sql.DBStats }) {
+	gauge := func(name, help string, value func(sql.DBStats) float64) {
+		r.NewGaugeFunc(name, help, nil, func(context.Context) ([]Sample, error) {
+			return []Sample{{Value: value(db.Stats())}}, nil
+		})
+	}
+	counter := func(name, help string, value func(sql.DBStats) float64) {
+		r.NewCounterFunc(name, help, nil, func(context.Context) ([]Sample, error) {
+			return []Sample{{Value: value(db.Stats())}}, nil
+		})
+	}
+	gauge("db_pool_max_open_connections", "Maximum number of open connections to the database.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_pool_open_connections", "Established connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_pool_in_use_connections", "Connections currently in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_pool_idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_pool_wait_count_total", "Connections waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_pool_wait_duration_seconds_total", "Time spent waiting for a connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_pool_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_pool_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("db_pool_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
+
+// PullRequests counts changes of pull requests made by this instance.
+type PullRequests struct {
+	created     *Counter
+	merged      *Counter
+	reassigned  *Counter
+	noCandidate *Counter
+}
+
+func NewPullRequests(r *Registry) *PullRequests {
+	return &PullRequests{
+		created:     r.NewCounter("pull_requests_created_total", "Pull requests created."),
+		merged:      r.NewCounter("pull_requests_merged_total", "Pull requests merged."),
+		reassigned:  r.NewCounter("reviewer_reassignments_total", "Reviewers replaced by reason.", "reason"),
+		noCandidate: r.NewCounter("reviewer_no_candidate_total", "Reassignments that failed with NO_CANDIDATE."),
+	}
+}
+
+func (m *PullRequests) Created() {
+	m.created.Inc()
+}
+
+func (m *PullRequests) Merged() {
+	m.merged.Inc()
+}
+
+func (m *PullRequests) Reassigned(reason string) {
+	m.reassigned.Inc(reason)
+}
+
+func (m *PullRequests) NoCandidate() {
+	m.noCandidate.Inc()
+}
+
+// OpenReviewsCounter counts assignments of OPEN pull requests by the reviewer's team.
+type OpenReviewsCounter interface {
+	CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// RegisterOpenReviews exposes the open reviews per team, counted on every scrape.
+func RegisterOpenReviews(r *Registry, counter OpenReviewsCounter) {
+	r.NewGaugeFunc("open_reviews", "Review assignments of open pull requests by the reviewer's team.", []string{"team"},
+		func(ctx context.Context) ([]Sample, error) {
+			counts, err := counter.CountOpenReviewsByTeam(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]Sample, 0, len(counts))
+			for team, n := range counts {
+				samples = append(samples, Sample{LabelValues: []string{team}, Value: float64(n)})
+			}
+			return samples, nil
+		})
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unmatchedRoute labels requests no route matched, so that unknown paths don't create series.
+const unmatchedRoute = "unmatched"
+
+// HTTP measures requests by method and chi route pattern.
+type HTTP struct {
+	requests *Counter
+	duration *Histogram
+}
+
+func NewHTTP(r *Registry) *HTTP {
+	return &HTTP{
+		requests: r.NewCounter("http_requests_total", "HTTP requests by method, route and status code.",
+			"method", "route", "code"),
+		duration: r.NewHistogram("http_request_duration_seconds", "HTTP request latency by method and route.",
+			DefaultBuckets, "method", "route"),
+	}
+}
+
+// Middleware records every request once the router has matched it, it has to wrap the chi router.
+func (h *HTTP) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		h.requests.Inc(r.Method, route, strconv.Itoa(recorder.status))
+		h.duration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
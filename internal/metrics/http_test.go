@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	registry := NewRegistry()
+	router := chi.NewRouter()
+	router.Use(NewHTTP(registry).Middleware)
+	router.Get("/team/get", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	for _, path := range []string{"/team/get", "/users/u1", "/users/u2", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, registry)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/team/get",code="404"} 1`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/users/{id}",code="200"} 2`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="unmatched",code="404"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`)
+}
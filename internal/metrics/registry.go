@@ -0,0 +1,331 @@
+// Package metrics exposes counters and histograms in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// metric is a family of samples sharing a name.
+type metric interface {
+	name() string
+	write(ctx context.Context, w *bufio.Writer) error
+}
+
+// Registry holds the metrics exposed by Handler.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, registered := range r.metrics {
+		if registered.name() == m.name() {
+			panic(fmt.Sprintf("metrics: %s is already registered", m.name()))
+		}
+	}
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes all metrics sorted by name, leaving out the ones that fail to collect.
+func (r *Registry) Write(ctx context.Context, w *bufio.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name() < metrics[j].name()
+	})
+	for _, m := range metrics {
+		if err := m.write(ctx, w); err != nil {
+			log.Println("metrics:", m.name(), err)
+		}
+	}
+	return w.Flush()
+}
+
+// Handler serves the metrics for scraping.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.Write(req.Context(), bufio.NewWriter(w)); err != nil {
+			fmt.Println(err)
+		}
+	})
+}
+
+// Counter is a counter partitioned by labels.
+type Counter struct {
+	metricName string
+	help       string
+	labels     []string
+	mu         sync.Mutex
+	series     map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{metricName: name, help: help, labels: labels, series: make(map[string]*counterSeries)}
+	r.register(c)
+	return c
+}
+
+// Inc adds 1 to the series with the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series with the label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: %s can't decrease", c.metricName))
+	}
+	key := seriesKey(c.metricName, c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: labelValues}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+func (c *Counter) name() string {
+	return c.metricName
+}
+
+func (c *Counter) write(_ context.Context, w *bufio.Writer) error {
+	c.mu.Lock()
+	samples := make([]Sample, 0, len(c.series))
+	for _, s := range c.series {
+		samples = append(samples, Sample{LabelValues: s.labelValues, Value: s.value})
+	}
+	c.mu.Unlock()
+	if len(samples) == 0 && len(c.labels) == 0 {
+		samples = append(samples, Sample{})
+	}
+	writeFamily(w, c.metricName, c.help, typeCounter, c.labels, samples)
+	return nil
+}
+
+// DefaultBuckets suit request latencies in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in buckets, partitioned by labels.
+type Histogram struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// NewHistogram registers a histogram with the upper bounds of the buckets in increasing order,
+// the +Inf bucket is implied.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	h := &Histogram{metricName: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// Observe adds v to the series with the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := seriesKey(h.metricName, h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) name() string {
+	return h.metricName
+}
+
+func (h *Histogram) write(_ context.Context, w *bufio.Writer) error {
+	h.mu.Lock()
+	series := make([]histogramSeries, 0, len(h.series))
+	for _, s := range h.series {
+		series = append(series, histogramSeries{
+			labelValues: s.labelValues,
+			counts:      append([]uint64(nil), s.counts...),
+			sum:         s.sum,
+			count:       s.count,
+		})
+	}
+	h.mu.Unlock()
+	sort.Slice(series, func(i, j int) bool {
+		return lessLabels(series[i].labelValues, series[j].labelValues)
+	})
+
+	writeHeader(w, h.metricName, h.help, typeHistogram)
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, s := range series {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.metricName+"_bucket", bucketLabels, append(append([]string(nil), s.labelValues...), formatFloat(bound)), float64(cumulative))
+		}
+		writeSample(w, h.metricName+"_bucket", bucketLabels, append(append([]string(nil), s.labelValues...), "+Inf"), float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, s.labelValues, s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, s.labelValues, float64(s.count))
+	}
+	return nil
+}
+
+// Sample is one value of a metric collected on scrape.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CollectFunc returns the current samples of a metric.
+type CollectFunc func(ctx context.Context) ([]Sample, error)
+
+type funcMetric struct {
+	metricName string
+	help       string
+	metricType metricType
+	labels     []string
+	collect    CollectFunc
+}
+
+// NewGaugeFunc registers a gauge whose samples are collected on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect CollectFunc) {
+	r.register(&funcMetric{metricName: name, help: help, metricType: typeGauge, labels: labels, collect: collect})
+}
+
+// NewCounterFunc registers a counter whose samples are collected on every scrape.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect CollectFunc) {
+	r.register(&funcMetric{metricName: name, help: help, metricType: typeCounter, labels: labels, collect: collect})
+}
+
+func (m *funcMetric) name() string {
+	return m.metricName
+}
+
+func (m *funcMetric) write(ctx context.Context, w *bufio.Writer) error {
+	samples, err := m.collect(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		if len(sample.LabelValues) != len(m.labels) {
+			return fmt.Errorf("got %d label values, want %d", len(sample.LabelValues), len(m.labels))
+		}
+	}
+	writeFamily(w, m.metricName, m.help, m.metricType, m.labels, samples)
+	return nil
+}
+
+func writeFamily(w *bufio.Writer, name, help string, typ metricType, labels []string, samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool {
+		return lessLabels(samples[i].LabelValues, samples[j].LabelValues)
+	})
+	writeHeader(w, name, help, typ)
+	for _, sample := range samples {
+		writeSample(w, name, labels, sample.LabelValues, sample.Value)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help string, typ metricType) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, typ)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, value float64) {
+	_, _ = w.WriteString(name)
+	if len(labels) > 0 {
+		_ = w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = fmt.Fprintf(w, "%s=\"%s\"", label, labelEscaper.Replace(labelValues[i]))
+		}
+		_ = w.WriteByte('}')
+	}
+	_, _ = fmt.Fprintf(w, " %s\n", formatFloat(value))
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func seriesKey(name string, labels, labelValues []string) string {
+	if len(labelValues) != len(labels) {
+		panic(fmt.Sprintf("metrics: %s got %d label values, want %d", name, len(labelValues), len(labels)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func lessLabels(a, b []string) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
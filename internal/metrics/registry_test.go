@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	var out strings.Builder
+	require.NoError(t, r.Write(context.Background(), bufio.NewWriter(&out)))
+	return out.String()
+}
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	created := r.NewCounter("prs_created_total", "Pull requests created.")
+	reassigned := r.NewCounter("reassignments_total", "Reassignments by reason.", "reason")
+	latency := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	r.NewGaugeFunc("open_reviews", "Open reviews.", []string{"team"}, func(context.Context) ([]Sample, error) {
+		return []Sample{{LabelValues: []string{"payments"}, Value: 2}, {LabelValues: []string{`back"end`}, Value: 5}}, nil
+	})
+	r.NewGaugeFunc("broken", "Fails to collect.", nil, func(context.Context) ([]Sample, error) {
+		return nil, errors.New("db is down")
+	})
+
+	created.Inc()
+	created.Inc()
+	reassigned.Inc("manual")
+	reassigned.Add(3, "escalated")
+	latency.Observe(0.05, "/team/get")
+	latency.Observe(0.5, "/team/get")
+	latency.Observe(3, "/team/get")
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/team/get",le="0.1"} 1
+latency_seconds_bucket{route="/team/get",le="1"} 2
+latency_seconds_bucket{route="/team/get",le="+Inf"} 3
+latency_seconds_sum{route="/team/get"} 3.55
+latency_seconds_count{route="/team/get"} 3
+# HELP open_reviews Open reviews.
+# TYPE open_reviews gauge
+open_reviews{team="back\"end"} 5
+open_reviews{team="payments"} 2
+# HELP prs_created_total Pull requests created.
+# TYPE prs_created_total counter
+prs_created_total 2
+# HELP reassignments_total Reassignments by reason.
+# TYPE reassignments_total counter
+reassignments_total{reason="escalated"} 3
+reassignments_total{reason="manual"} 1
+`, scrape(t, r))
+}
+
+func TestRegistryRejectsMisuse(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounter("requests_total", "Requests.", "method")
+	assert.Panics(t, func() { r.NewCounter("requests_total", "Again.") })
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Add(-1, "GET") })
+	assert.Panics(t, func() { r.NewHistogram("latency", "Latency.", []float64{1, 0.1}) })
+}
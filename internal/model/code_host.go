@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type CodeHostSyncStatus string
+
+const (
+	SyncPending CodeHostSyncStatus = "PENDING"
+	SyncSynced  CodeHostSyncStatus = "SYNCED"
+	// SyncFailed means the sync ran out of attempts or the code host refused the change.
+	SyncFailed CodeHostSyncStatus = "FAILED"
+	// SyncSkipped is set for pull requests that don't come from the code host.
+	SyncSkipped CodeHostSyncStatus = "SKIPPED"
+)
+
+// CodeHostSync tracks mirroring of the assigned reviewers of a pull request to the code host.
+type CodeHostSync struct {
+	PullRequestID string
+	Status        CodeHostSyncStatus
+	Revision      int64
+	Attempts      int
+	// SyncedReviewers are the code host logins whose review was requested by the last successful sync.
+	SyncedReviewers []string
+	LastError       string
+	NextAttemptAt   time.Time
+	SyncedAt        time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+// GitHubUser links a GitHub login to a user of the service.
+type GitHubUser struct {
+	Login  string
+	UserID string
+}
+
+// GitHubDelivery is an incoming GitHub webhook request.
+type GitHubDelivery struct {
+	ID        string
+	Event     string
+	Signature string
+	Body      []byte
+}
+
+type GitHubDeliveryStatus string
+
+const (
+	GitHubProcessed GitHubDeliveryStatus = "processed"
+	GitHubDuplicate GitHubDeliveryStatus = "duplicate"
+	GitHubIgnored   GitHubDeliveryStatus = "ignored"
+)
+
+type GitHubDeliveryResult struct {
+	DeliveryID    string
+	Status        GitHubDeliveryStatus
+	Action        string
+	PullRequestID string
+	// Reason explains why an ignored delivery was not applied.
+	Reason string
+}
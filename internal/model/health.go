@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type CheckStatus string
+
+const (
+	CheckOK       CheckStatus = "ok"
+	CheckFail     CheckStatus = "fail"
+	CheckDisabled CheckStatus = "disabled"
+)
+
+// HealthCheck is the outcome of one readiness check. LastRunAt is only set for background workers.
+type HealthCheck struct {
+	Name      string
+	Status    CheckStatus
+	Message   string
+	LastRunAt time.Time
+}
+
+// Readiness is ready when none of its checks failed.
+type Readiness struct {
+	Ready  bool
+	Checks []HealthCheck
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Percentiles of a duration, nil when there was nothing to measure.
+type Percentiles struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+}
+
+// Latency describes how long merged pull requests waited: from creation to merge and
+// from the reviewer assignment to merge.
+type Latency struct {
+	MergedPrs         int
+	Assignments       int
+	TimeToMerge       *Percentiles
+	AssignmentToMerge *Percentiles
+}
+
+// TeamLatency is Latency of pull requests authored by the team members.
+type TeamLatency struct {
+	TeamName string
+	Latency
+}
+
+// ReviewerLatency is Latency of pull requests the reviewer was assigned to.
+type ReviewerLatency struct {
+	ReviewerID string
+	TeamName   string
+	Latency
+}
+
+// WeeklyLatency is Latency of pull requests merged within the week starting on WeekStart.
+type WeeklyLatency struct {
+	WeekStart time.Time
+	Latency
+}
+
+// LatencyReport covers pull requests merged within [From, To).
+type LatencyReport struct {
+	From      time.Time
+	To        time.Time
+	Overall   Latency
+	Teams     []TeamLatency
+	Reviewers []ReviewerLatency
+	Weekly    []WeeklyLatency
+}
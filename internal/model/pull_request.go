@@ -5,6 +5,8 @@ import "time"
 var (
 	StatusOpen  PullRequestStatus = "OPEN"
 	StatusMerge PullRequestStatus = "MERGED"
+	// StatusClosed marks an abandoned pull request, it can be reopened.
+	StatusClosed PullRequestStatus = "CLOSED"
 )
 
 type PullRequestStatus string
@@ -13,6 +15,11 @@ type CreatePullRequest struct {
 	AuthorID        string
 	PullRequestID   string
 	PullRequestName string
+	// Repository selects the CODEOWNERS file of the author's team, ChangedFiles are matched against it.
+	Repository   string
+	ChangedFiles []string
+	// Labels are matched against reviewer skills.
+	Labels []string
 }
 
 type PullRequest struct {
@@ -22,5 +29,120 @@ type PullRequest struct {
 	Status          PullRequestStatus
 	CreatedAt       time.Time
 	MergedAt        time.Time
+	ClosedAt        time.Time
+	Labels          []string
 	ReviewerIDs     []string
+	Reviewers       []Reviewer
+	// NeedsReviewer is set when a reviewer was deactivated and nobody could take over the review.
+	NeedsReviewer bool
+}
+
+type Reviewer struct {
+	ID         string
+	TeamName   string
+	AssignedAt time.Time
+	IsActive   bool
+	// Verdict is the latest verdict of the reviewer, empty until the first review.
+	Verdict   ReviewVerdict
+	VerdictAt time.Time
+	// IsFallback is set when the reviewer was taken from a fallback team of the author's team.
+	IsFallback bool
+	// IsCodeOwner is set when the reviewer was chosen because CODEOWNERS assigns them changed files.
+	IsCodeOwner bool
+	// MatchedTags are the skills of the reviewer that matched the pull request labels.
+	MatchedTags []string
+	// DueAt is when the review has to start by the team SLA, zero without an SLA.
+	DueAt time.Time
+	// IsOverdue is set while the pull request is OPEN, DueAt has passed and the reviewer left no review.
+	IsOverdue bool
+}
+
+type ReviewVerdict string
+
+const (
+	VerdictApproved         ReviewVerdict = "APPROVED"
+	VerdictChangesRequested ReviewVerdict = "CHANGES_REQUESTED"
+	VerdictCommented        ReviewVerdict = "COMMENTED"
+)
+
+func (v ReviewVerdict) IsValid() bool {
+	switch v {
+	case VerdictApproved, VerdictChangesRequested, VerdictCommented:
+		return true
+	}
+	return false
+}
+
+// Review is a verdict submitted by an assigned reviewer.
+type Review struct {
+	PullRequestID string
+	ReviewerID    string
+	Verdict       ReviewVerdict
+	Comment       string
+}
+
+// Reassignment describes a review moved from a deactivated reviewer.
+// NewReviewerID is empty when no candidate was found.
+type Reassignment struct {
+	PullRequestID   string
+	OldReviewerID   string
+	NewReviewerID   string
+	NewReviewerTeam string
+	IsFallback      bool
+	MatchedTags     []string
+	DueAt           time.Time
+}
+
+type PullRequestEventType string
+
+const (
+	EventCreated       PullRequestEventType = "CREATED"
+	EventAssigned      PullRequestEventType = "ASSIGNED"
+	EventReassigned    PullRequestEventType = "REASSIGNED"
+	EventNeedsReviewer PullRequestEventType = "NEEDS_REVIEWER"
+	EventReviewed      PullRequestEventType = "REVIEWED"
+	EventMerged        PullRequestEventType = "MERGED"
+	EventClosed        PullRequestEventType = "CLOSED"
+	EventReopened      PullRequestEventType = "REOPENED"
+	// EventReminded is recorded when a reviewer is reminded of a review that went stale.
+	EventReminded PullRequestEventType = "REMINDED"
+)
+
+var EventTypes = []PullRequestEventType{
+	EventCreated,
+	EventAssigned,
+	EventReassigned,
+	EventNeedsReviewer,
+	EventReviewed,
+	EventMerged,
+	EventClosed,
+	EventReopened,
+	EventReminded,
+}
+
+// Reasons recorded with assignment events.
+const (
+	ReasonFallbackTeam        = "fallback_team"
+	ReasonCodeOwner           = "code_owner"
+	ReasonManual              = "manual"
+	ReasonReviewerDeactivated = "reviewer_deactivated"
+	ReasonReviewerInactive    = "reviewer_inactive_on_reopen"
+	// ReasonEscalated is recorded when a stale review is handed to someone else automatically.
+	ReasonEscalated = "escalated"
+)
+
+// PullRequestEvent is an entry of the append-only pull request history.
+// Fields that don't apply to the event type are empty.
+type PullRequestEvent struct {
+	ID            int64
+	PullRequestID string
+	Type          PullRequestEventType
+	Actor         string
+	ReviewerID    string
+	OldReviewerID string
+	FromStatus    PullRequestStatus
+	ToStatus      PullRequestStatus
+	Verdict       ReviewVerdict
+	Reason        string
+	CreatedAt     time.Time
 }
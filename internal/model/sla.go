@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// OverdueReview is a review assignment of an OPEN pull request whose SLA deadline passed
+// before the reviewer left any review.
+type OverdueReview struct {
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	ReviewerID      string
+	ReviewerTeam    string
+	AssignedAt      time.Time
+	DueAt           time.Time
+}
+
+// OverdueFilter narrows overdue reviews down to reviewers of a team and/or one reviewer, empty fields match all.
+type OverdueFilter struct {
+	TeamName string
+	UserID   string
+}
+
+// SLACompliance counts review assignments of pull requests merged within [From, To) that had an SLA,
+// and how many of them got the first review before the deadline.
+type SLACompliance struct {
+	From      time.Time
+	To        time.Time
+	Total     int
+	WithinSLA int
+	Teams     []TeamSLACompliance
+}
+
+// TeamSLACompliance is SLACompliance of the reviewers of one team.
+type TeamSLACompliance struct {
+	TeamName  string `db:"team_name"`
+	Total     int    `db:"total"`
+	WithinSLA int    `db:"within_sla"`
+}
+
+// CompliancePercent returns the share of reviews started within the SLA, nil when there were none.
+func CompliancePercent(withinSLA, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	percent := float64(withinSLA) * 100 / float64(total)
+	return &percent
+}
+
+// StaleReview is a review assignment of an OPEN pull request the reviewer hasn't reviewed yet.
+// RemindedAt and EscalatedAt are zero until the reminder worker acts on it.
+type StaleReview struct {
+	PullRequestID string
+	ReviewerID    string
+	AssignedAt    time.Time
+	RemindedAt    time.Time
+	EscalatedAt   time.Time
+}
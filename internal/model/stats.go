@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type UserStatistics struct {
 	UserId   string `json:"user_id" db:"user_id"`
 	Username string `json:"username" db:"username"`
@@ -9,12 +11,33 @@ type UserStatistics struct {
 	TotalReviewAssignments  int `json:"total_review_assignments" db:"total_review_assignments"`
 	OpenReviewAssignments   int `json:"open_review_assignments" db:"open_review_assignments"`
 	MergedReviewAssignments int `json:"merged_review_assignments" db:"merged_review_assignments"`
+	ClosedReviewAssignments int `json:"closed_review_assignments" db:"closed_review_assignments"`
 
 	TotalAuthoredPrs  int `json:"total_authored_prs" db:"total_authored_prs"`
 	OpenAuthoredPrs   int `json:"open_authored_prs" db:"open_authored_prs"`
 	MergedAuthoredPrs int `json:"merged_authored_prs" db:"merged_authored_prs"`
+	ClosedAuthoredPrs int `json:"closed_authored_prs" db:"closed_authored_prs"`
 }
 
 type StatsResponse struct {
 	Statistics []UserStatistics `json:"statistics"`
 }
+
+// StatsFilter narrows statistics down to a period and a team. Zero From or To leave that side open.
+type StatsFilter struct {
+	From     time.Time
+	To       time.Time
+	TeamName string
+}
+
+// TeamStatistics aggregates pull requests authored by the team members and the open review load of its active members.
+type TeamStatistics struct {
+	TeamName          string  `db:"team_name"`
+	OpenedPrs         int     `db:"opened_prs"`
+	MergedPrs         int     `db:"merged_prs"`
+	AvgReviewersPerPr float64 `db:"avg_reviewers_per_pr"`
+	ActiveMembers     int     `db:"active_members"`
+	MinOpenReviews    int     `db:"min_open_reviews"`
+	MaxOpenReviews    int     `db:"max_open_reviews"`
+	StddevOpenReviews float64 `db:"stddev_open_reviews"`
+}
@@ -1,12 +1,121 @@
 package model
 
+import "time"
+
 type Member struct {
 	ID       string
 	Name     string
 	IsActive bool
+	// Absence is the absence the member is on right now, nil when the member is available.
+	Absence *Absence
 }
 
 type Team struct {
 	Name    string
 	Members []Member
+	// Rotation is the order in which active members get the next round-robin review.
+	Rotation []string
+}
+
+// RotationMove moves the round-robin cursor of a team From the last member who got a review To the new one.
+type RotationMove struct {
+	TeamName string
+	From     string
+	To       string
+}
+
+// TeamAddResult reports which members /team/add created, updated or moved from another team.
+type TeamAddResult struct {
+	Team    Team
+	Created []string
+	Updated []string
+	Moved   []MovedMember
+}
+
+type TeamUpdate struct {
+	Name   string
+	Add    []Member
+	Remove []string
+}
+
+type TeamUpdateResult struct {
+	TeamAddResult
+	Removed []string
+}
+
+type TeamDeletePolicy string
+
+const (
+	// DeletePolicyRefuse deletes the team only when its members have no OPEN reviews.
+	DeletePolicyRefuse TeamDeletePolicy = "refuse_if_open_reviews"
+	// DeletePolicyMove moves members, together with their reviews, to another team.
+	DeletePolicyMove TeamDeletePolicy = "move_members"
+)
+
+type TeamDelete struct {
+	Name   string
+	Policy TeamDeletePolicy
+	MoveTo string
+}
+
+type MovedMember struct {
+	UserID   string
+	FromTeam string
+}
+
+type TeamSettings struct {
+	TeamName            string
+	MinReviewers        int
+	MaxReviewers        int
+	AllowInactiveAuthor bool
+	// Strategy is a reviewer selection strategy name, empty means the deployment default.
+	Strategy string
+	// RequiredApprovals is how many assigned reviewers must approve before merge, 0 disables the check.
+	RequiredApprovals int
+	// AbsenceLookaheadHours also skips reviewers whose absence starts within that many hours.
+	AbsenceLookaheadHours int
+	// ReviewSLAHours is how many working hours of the reviewer a review may wait to start, 0 disables the SLA.
+	ReviewSLAHours int
+}
+
+// ReviewSLA returns ReviewSLAHours as a duration.
+func (s TeamSettings) ReviewSLA() time.Duration {
+	return time.Duration(s.ReviewSLAHours) * time.Hour
+}
+
+// AbsenceLookahead returns AbsenceLookaheadHours as a duration.
+func (s TeamSettings) AbsenceLookahead() time.Duration {
+	return time.Duration(s.AbsenceLookaheadHours) * time.Hour
+}
+
+func DefaultTeamSettings(teamName string) TeamSettings {
+	return TeamSettings{
+		TeamName:            teamName,
+		MinReviewers:        1,
+		MaxReviewers:        2,
+		AllowInactiveAuthor: true,
+	}
+}
+
+// DeactivateMembers selects team members to deactivate, nil FallbackTeams means the stored ones.
+type DeactivateMembers struct {
+	TeamName      string
+	UserIDs       []string
+	All           bool
+	FallbackTeams []string
+}
+
+type DeactivationReport struct {
+	TeamName        string
+	Deactivated     []string
+	AlreadyInactive []string
+	Reassignments   []Reassignment
+}
+
+// CodeOwners is a CODEOWNERS file a team uploaded for one of its repositories.
+type CodeOwners struct {
+	TeamName   string
+	Repository string
+	Content    string
+	UpdatedAt  time.Time
 }
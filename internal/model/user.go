@@ -1,8 +1,50 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 type User struct {
 	ID       string
 	Name     string
 	TeamName string
 	IsActive bool
 }
+
+// Skill is an expertise tag of a user. Level is an optional proficiency from 1 to 5, 0 when not set.
+type Skill struct {
+	Tag   string
+	Level int
+}
+
+const MaxSkillLevel = 5
+
+// NormalizeTag makes skill tags and pull request labels comparable: `Payments ` matches `payments`.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Absence is a period when the user is away, e.g. on vacation. The user is not picked as a reviewer
+// from StartsAt until EndsAt, regardless of IsActive.
+type Absence struct {
+	ID        int64
+	UserID    string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Reason    string
+	CreatedAt time.Time
+}
+
+// WorkSchedule holds the working hours of a user: from StartMinute to EndMinute after local midnight
+// in Timezone on each of Weekdays. The zero value means the user has no schedule and is always working.
+type WorkSchedule struct {
+	Timezone    string
+	StartMinute int
+	EndMinute   int
+	Weekdays    []time.Weekday
+}
+
+func (s WorkSchedule) IsZero() bool {
+	return s.Timezone == ""
+}
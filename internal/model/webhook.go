@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+type WebhookSubscription struct {
+	ID     int64
+	URL    string
+	Secret string
+	// EventTypes filters the events sent to the subscriber, empty means all of them.
+	EventTypes []PullRequestEventType
+	IsActive   bool
+	CreatedAt  time.Time
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "PENDING"
+	DeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// DeliveryFailed means the delivery ran out of attempts.
+	DeliveryFailed WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is an outbox entry: one event to be sent to one subscription.
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	URL            string
+	Secret         string
+	Event          PullRequestEvent
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time
+	DeliveredAt    time.Time
+	CreatedAt      time.Time
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionID int64
+	Status         WebhookDeliveryStatus
+	Limit          int
+}
@@ -0,0 +1,130 @@
+// Package reminder reminds reviewers of stale reviews and escalates the ones left stale for too long.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/actor"
+	"github.com/doverlof/avito_help/internal/client/repo/lock"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/model"
+	pullRequestUseCasePkg "github.com/doverlof/avito_help/internal/usecase/pull-request"
+)
+
+// Actor attributes the events the worker records.
+const Actor = "system:reminder"
+
+// Store keeps track of stale reviews.
+type Store interface {
+	GetStale(ctx context.Context, remindBefore, escalateBefore time.Time, limit int) ([]model.StaleReview, error)
+	MarkReminded(ctx context.Context, pullRequestID, reviewerID string) error
+	MarkEscalated(ctx context.Context, pullRequestID, reviewerID string) error
+}
+
+// Escalator reassigns a review through the normal reassignment path.
+type Escalator interface {
+	Escalate(ctx context.Context, pullRequestID, reviewerID string) (model.PullRequest, string, error)
+}
+
+type Worker struct {
+	store     Store
+	escalator Escalator
+	locker    lock.Locker
+	cfg       config.ReminderConfig
+	component *health.Component
+	now       func() time.Time
+}
+
+func NewWorker(cfg config.ReminderConfig, store Store, escalator Escalator, locker lock.Locker, component *health.Component) *Worker {
+	return &Worker{
+		store:     store,
+		escalator: escalator,
+		locker:    locker,
+		cfg:       cfg,
+		component: component,
+		now:       time.Now,
+	}
+}
+
+// Run checks for stale reviews until ctx is cancelled, only the instance holding the advisory lock works.
+func (w *Worker) Run(ctx context.Context) {
+	if w.cfg.RemindAfter <= 0 && w.cfg.EscalateAfter <= 0 {
+		w.component.Disable()
+		return
+	}
+	ctx = actor.WithActor(ctx, Actor)
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		err := w.tick(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("reminder worker:", err)
+		}
+		w.component.Ran(err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) tick(ctx context.Context) error {
+	release, ok, err := w.locker.TryLock(ctx, lock.KeyReminder)
+	if err != nil || !ok {
+		return err
+	}
+	defer release()
+	_, err = w.RemindDue(ctx)
+	return err
+}
+
+// RemindDue reminds or escalates one batch of stale reviews and returns how many were handled.
+func (w *Worker) RemindDue(ctx context.Context) (int, error) {
+	now := w.now()
+	var remindBefore, escalateBefore time.Time
+	if w.cfg.RemindAfter > 0 {
+		remindBefore = now.Add(-w.cfg.RemindAfter)
+	}
+	if w.cfg.EscalateAfter > 0 {
+		escalateBefore = now.Add(-w.cfg.EscalateAfter)
+	}
+	stale, err := w.store.GetStale(ctx, remindBefore, escalateBefore, w.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, review := range stale {
+		if !escalateBefore.IsZero() && review.AssignedAt.Before(escalateBefore) {
+			err = w.escalate(ctx, review)
+		} else {
+			err = w.store.MarkReminded(ctx, review.PullRequestID, review.ReviewerID)
+		}
+		if err != nil {
+			return len(stale), err
+		}
+	}
+	return len(stale), nil
+}
+
+func (w *Worker) escalate(ctx context.Context, review model.StaleReview) error {
+	_, _, err := w.escalator.Escalate(ctx, review.PullRequestID, review.ReviewerID)
+	switch {
+	case errors.Is(err, pullRequestUseCasePkg.ErrDontHaveReviewers),
+		errors.Is(err, pullRequestUseCasePkg.ErrTeamOrAuthorNotFound):
+		return w.store.MarkEscalated(ctx, review.PullRequestID, review.ReviewerID)
+	case errors.Is(err, pullRequestUseCasePkg.ErrPRNotFound),
+		errors.Is(err, pullRequestUseCasePkg.ErrPRAlreadyMerged),
+		errors.Is(err, pullRequestUseCasePkg.ErrPRClosed),
+		errors.Is(err, pullRequestUseCasePkg.ErrNotAssigned):
+		// The review moved on since it was found stale.
+		return nil
+	}
+	return err
+}
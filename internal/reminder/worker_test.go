@@ -0,0 +1,141 @@
+package reminder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/actor"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+	pullRequestUseCasePkg "github.com/doverlof/avito_help/internal/usecase/pull-request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore mimics the stale review columns of pr_reviewers.
+type memoryStore struct {
+	reviews   []*model.StaleReview
+	reminded  []string
+	escalated []string
+}
+
+func (s *memoryStore) GetStale(_ context.Context, remindBefore, escalateBefore time.Time, limit int) ([]model.StaleReview, error) {
+	res := make([]model.StaleReview, 0)
+	for _, review := range s.reviews {
+		if len(res) == limit {
+			break
+		}
+		if !review.EscalatedAt.IsZero() {
+			continue
+		}
+		remind := !remindBefore.IsZero() && review.RemindedAt.IsZero() && review.AssignedAt.Before(remindBefore)
+		escalate := !escalateBefore.IsZero() && review.AssignedAt.Before(escalateBefore)
+		if remind || escalate {
+			res = append(res, *review)
+		}
+	}
+	return res, nil
+}
+
+func (s *memoryStore) MarkReminded(_ context.Context, pullRequestID, reviewerID string) error {
+	s.find(pullRequestID, reviewerID).RemindedAt = time.Now()
+	s.reminded = append(s.reminded, pullRequestID+"/"+reviewerID)
+	return nil
+}
+
+func (s *memoryStore) MarkEscalated(_ context.Context, pullRequestID, reviewerID string) error {
+	s.find(pullRequestID, reviewerID).EscalatedAt = time.Now()
+	return nil
+}
+
+func (s *memoryStore) find(pullRequestID, reviewerID string) *model.StaleReview {
+	for _, review := range s.reviews {
+		if review.PullRequestID == pullRequestID && review.ReviewerID == reviewerID {
+			return review
+		}
+	}
+	return nil
+}
+
+// reopen restarts the assignments of a pull request like the repo does when it is reopened.
+func (s *memoryStore) reopen(pullRequestID string, at time.Time) {
+	for _, review := range s.reviews {
+		if review.PullRequestID == pullRequestID {
+			review.AssignedAt, review.RemindedAt, review.EscalatedAt = at, time.Time{}, time.Time{}
+		}
+	}
+}
+
+// fakeEscalator hands reviews to "u9" unless the pull request has no candidates left.
+type fakeEscalator struct {
+	store        *memoryStore
+	noCandidates map[string]bool
+	actors       []string
+}
+
+func (e *fakeEscalator) Escalate(ctx context.Context, pullRequestID, reviewerID string) (model.PullRequest, string, error) {
+	e.actors = append(e.actors, actor.FromContext(ctx))
+	if e.noCandidates[pullRequestID] {
+		return model.PullRequest{}, "", pullRequestUseCasePkg.ErrDontHaveReviewers
+	}
+	review := e.store.find(pullRequestID, reviewerID)
+	review.ReviewerID, review.AssignedAt, review.RemindedAt = "u9", time.Now(), time.Time{}
+	e.store.escalated = append(e.store.escalated, pullRequestID+"/"+reviewerID)
+	return model.PullRequest{PullRequestID: pullRequestID}, "u9", nil
+}
+
+func TestRemindDue(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{reviews: []*model.StaleReview{
+		{PullRequestID: "pr-1", ReviewerID: "u1", AssignedAt: now.Add(-100 * time.Hour)},
+		{PullRequestID: "pr-2", ReviewerID: "u2", AssignedAt: now.Add(-30 * time.Hour)},
+		{PullRequestID: "pr-3", ReviewerID: "u3", AssignedAt: now.Add(-80 * time.Hour), RemindedAt: now.Add(-50 * time.Hour)},
+		{PullRequestID: "pr-4", ReviewerID: "u4", AssignedAt: now.Add(-time.Hour)},
+	}}
+	escalator := &fakeEscalator{store: store, noCandidates: map[string]bool{"pr-3": true}}
+	worker := NewWorker(config.ReminderConfig{BatchSize: 10, RemindAfter: 24 * time.Hour, EscalateAfter: 72 * time.Hour}, store, escalator, nil, nil)
+	ctx := actor.WithActor(context.Background(), Actor)
+
+	n, err := worker.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"pr-2/u2"}, store.reminded)
+	assert.Equal(t, []string{"pr-1/u1"}, store.escalated)
+	assert.False(t, store.find("pr-3", "u3").EscalatedAt.IsZero(), "a review nobody can take over is not retried")
+	assert.Equal(t, []string{Actor, Actor}, escalator.actors)
+
+	n, err = worker.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "reminders and escalations happen once")
+}
+
+func TestRemindReopened(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{reviews: []*model.StaleReview{
+		{PullRequestID: "pr-1", ReviewerID: "u1", AssignedAt: now.Add(-30 * time.Hour), RemindedAt: now.Add(-5 * time.Hour)},
+		{PullRequestID: "pr-2", ReviewerID: "u2", AssignedAt: now.Add(-100 * time.Hour), EscalatedAt: now.Add(-20 * time.Hour)},
+	}}
+	escalator := &fakeEscalator{store: store, noCandidates: map[string]bool{}}
+	worker := NewWorker(config.ReminderConfig{BatchSize: 10, RemindAfter: 24 * time.Hour, EscalateAfter: 72 * time.Hour}, store, escalator, nil, nil)
+	ctx := actor.WithActor(context.Background(), Actor)
+	store.reopen("pr-1", now)
+	store.reopen("pr-2", now)
+
+	n, err := worker.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the time spent closed doesn't count")
+
+	worker.now = func() time.Time { return now.Add(25 * time.Hour) }
+	n, err = worker.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"pr-1/u1", "pr-2/u2"}, store.reminded)
+	assert.Empty(t, store.escalated)
+
+	worker.now = func() time.Time { return now.Add(73 * time.Hour) }
+	n, err = worker.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"pr-1/u1", "pr-2/u2"}, store.escalated, "a review escalated before closing is chased again")
+}
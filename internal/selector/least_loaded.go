@@ -0,0 +1,38 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type leastLoaded struct {
+	loads LoadCounter
+	rnd   *lockedRand
+}
+
+// NewLeastLoaded picks reviewers with the fewest open reviews, ties are broken randomly.
+func NewLeastLoaded(loads LoadCounter, seed int64) ReviewerSelector {
+	return &leastLoaded{loads: loads, rnd: newLockedRand(seed)}
+}
+
+func (s *leastLoaded) Select(ctx context.Context, candidates []model.User, n int) ([]model.User, error) {
+	if len(candidates) == 0 {
+		return []model.User{}, nil
+	}
+	loads, err := s.loads.CountOpenReviews(ctx, userIDs(candidates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+
+	res := shuffled(s.rnd, candidates)
+	sort.SliceStable(res, func(i, j int) bool {
+		return loads[res[i].ID] < loads[res[j].ID]
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res, nil
+}
@@ -0,0 +1,24 @@
+package selector
+
+import (
+	"context"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type random struct {
+	rnd *lockedRand
+}
+
+// NewRandom picks reviewers uniformly at random.
+func NewRandom(seed int64) ReviewerSelector {
+	return &random{rnd: newLockedRand(seed)}
+}
+
+func (s *random) Select(_ context.Context, candidates []model.User, n int) ([]model.User, error) {
+	res := shuffled(s.rnd, candidates)
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res, nil
+}
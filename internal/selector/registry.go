@@ -0,0 +1,58 @@
+package selector
+
+import (
+	"fmt"
+
+	"github.com/doverlof/avito_help/internal/config"
+)
+
+// Resolver returns the selector for a team strategy, an empty strategy means the deployment default.
+type Resolver interface {
+	For(strategy string, rotation RotationStore) ReviewerSelector
+}
+
+type Registry struct {
+	defaultStrategy Strategy
+	selectors       map[Strategy]ReviewerSelector
+}
+
+func NewRegistry(cfg config.SelectorConfig, loads LoadCounter, schedules ScheduleSource) (*Registry, error) {
+	if !IsKnown(cfg.Strategy) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, cfg.Strategy)
+	}
+	registry := &Registry{
+		defaultStrategy: Strategy(cfg.Strategy),
+		selectors:       make(map[Strategy]ReviewerSelector, len(Strategies)),
+	}
+	for _, strategy := range Strategies {
+		if strategy == StrategyRoundRobin {
+			continue
+		}
+		s, err := New(config.SelectorConfig{Strategy: string(strategy), Seed: cfg.Seed}, loads, nil, schedules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init %s selector: %w", strategy, err)
+		}
+		registry.selectors[strategy] = s
+	}
+	return registry, nil
+}
+
+func (r *Registry) For(strategy string, rotation RotationStore) ReviewerSelector {
+	s := Strategy(strategy)
+	if !IsKnown(strategy) {
+		s = r.defaultStrategy
+	}
+	if s == StrategyRoundRobin {
+		return NewRoundRobin(rotation)
+	}
+	return r.selectors[s]
+}
+
+func IsKnown(strategy string) bool {
+	for _, s := range Strategies {
+		if string(s) == strategy {
+			return true
+		}
+	}
+	return false
+}
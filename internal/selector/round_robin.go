@@ -0,0 +1,50 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+// RotationStore hands out the next members in turn for a team.
+type RotationStore interface {
+	Next(ctx context.Context, teamName string, candidateIDs []string, n int) ([]string, error)
+}
+
+type roundRobin struct {
+	rotation RotationStore
+}
+
+// NewRoundRobin gives every active team member the next review in turn.
+func NewRoundRobin(rotation RotationStore) ReviewerSelector {
+	return &roundRobin{rotation: rotation}
+}
+
+func (s *roundRobin) Select(ctx context.Context, candidates []model.User, n int) ([]model.User, error) {
+	byID := make(map[string]model.User, len(candidates))
+	teams := make([]string, 0, 1)
+	idsByTeam := make(map[string][]string)
+	for _, c := range candidates {
+		byID[c.ID] = c
+		if _, ok := idsByTeam[c.TeamName]; !ok {
+			teams = append(teams, c.TeamName)
+		}
+		idsByTeam[c.TeamName] = append(idsByTeam[c.TeamName], c.ID)
+	}
+
+	res := make([]model.User, 0, n)
+	for _, team := range teams {
+		if len(res) >= n {
+			break
+		}
+		ids, err := s.rotation.Next(ctx, team, idsByTeam[team], n-len(res))
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance rotation: %w", err)
+		}
+		for _, id := range ids {
+			res = append(res, byID[id])
+		}
+	}
+	return res, nil
+}
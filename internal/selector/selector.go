@@ -0,0 +1,96 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type Strategy string
+
+const (
+	StrategyRandom      Strategy = "random"
+	StrategyLeastLoaded Strategy = "least_loaded"
+	StrategyWeighted    Strategy = "weighted"
+	StrategyRoundRobin  Strategy = "round_robin"
+	// StrategyWorkingHours prefers reviewers inside their working hours, see NewWorkingHours.
+	StrategyWorkingHours Strategy = "working_hours"
+)
+
+var Strategies = []Strategy{StrategyRandom, StrategyLeastLoaded, StrategyWeighted, StrategyRoundRobin, StrategyWorkingHours}
+
+var ErrUnknownStrategy = errors.New("unknown reviewer selection strategy")
+
+// ReviewerSelector picks up to n reviewers from already filtered candidates.
+type ReviewerSelector interface {
+	Select(ctx context.Context, candidates []model.User, n int) ([]model.User, error)
+}
+
+// LoadCounter returns the number of OPEN pull requests each user is reviewing.
+type LoadCounter interface {
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+}
+
+func New(cfg config.SelectorConfig, loads LoadCounter, rotation RotationStore, schedules ScheduleSource) (ReviewerSelector, error) {
+	switch Strategy(cfg.Strategy) {
+	case StrategyRandom:
+		return NewRandom(cfg.Seed), nil
+	case StrategyLeastLoaded:
+		return NewLeastLoaded(loads, cfg.Seed), nil
+	case StrategyWeighted:
+		return NewWeighted(loads, cfg.Seed), nil
+	case StrategyRoundRobin:
+		return NewRoundRobin(rotation), nil
+	case StrategyWorkingHours:
+		return NewWorkingHours(loads, schedules, cfg.Seed), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, cfg.Strategy)
+	}
+}
+
+// lockedRand is a rand.Rand safe for concurrent use. Seed 0 means "seed from the clock".
+type lockedRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &lockedRand{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (r *lockedRand) Perm(n int) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Perm(n)
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64()
+}
+
+func shuffled(rnd *lockedRand, candidates []model.User) []model.User {
+	res := make([]model.User, len(candidates))
+	for i, j := range rnd.Perm(len(candidates)) {
+		res[i] = candidates[j]
+	}
+	return res
+}
+
+func userIDs(users []model.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
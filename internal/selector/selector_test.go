@@ -0,0 +1,216 @@
+package selector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/client/repo/rotation"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticLoads map[string]int
+
+func (l staticLoads) CountOpenReviews(_ context.Context, userIDs []string) (map[string]int, error) {
+	res := make(map[string]int, len(userIDs))
+	for _, id := range userIDs {
+		res[id] = l[id]
+	}
+	return res, nil
+}
+
+type staticSchedules map[string]model.WorkSchedule
+
+func (s staticSchedules) GetSchedules(_ context.Context, userIDs []string) (map[string]model.WorkSchedule, error) {
+	res := make(map[string]model.WorkSchedule, len(userIDs))
+	for _, id := range userIDs {
+		if schedule, ok := s[id]; ok {
+			res[id] = schedule
+		}
+	}
+	return res, nil
+}
+
+type memoryRotation struct {
+	cursors map[string]string
+}
+
+func (r *memoryRotation) Next(_ context.Context, teamName string, candidateIDs []string, n int) ([]string, error) {
+	if r.cursors == nil {
+		r.cursors = map[string]string{}
+	}
+	picked := rotation.Order(candidateIDs, r.cursors[teamName])
+	if len(picked) > n {
+		picked = picked[:n]
+	}
+	r.cursors[teamName] = picked[len(picked)-1]
+	return picked, nil
+}
+
+func users(ids ...string) []model.User {
+	res := make([]model.User, len(ids))
+	for i, id := range ids {
+		res[i] = model.User{ID: id, TeamName: "backend", IsActive: true}
+	}
+	return res
+}
+
+func TestRandomIsDeterministicWithSeed(t *testing.T) {
+	ctx := context.Background()
+	candidates := users("u1", "u2", "u3", "u4", "u5")
+
+	first, err := NewRandom(42).Select(ctx, candidates, 2)
+	require.NoError(t, err)
+	second, err := NewRandom(42).Select(ctx, candidates, 2)
+	require.NoError(t, err)
+
+	assert.Len(t, first, 2)
+	assert.Equal(t, first, second)
+}
+
+func TestLeastLoaded(t *testing.T) {
+	ctx := context.Background()
+	loads := staticLoads{"u1": 5, "u2": 0, "u3": 3, "u4": 1}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{name: "one", n: 1, want: []string{"u2"}},
+		{name: "two", n: 2, want: []string{"u2", "u4"}},
+		{name: "more than candidates", n: 10, want: []string{"u2", "u4", "u3", "u1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewLeastLoaded(loads, 1).Select(ctx, users("u1", "u2", "u3", "u4"), test.n)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, userIDs(got))
+		})
+	}
+}
+
+func TestLeastLoadedBreaksTiesRandomly(t *testing.T) {
+	ctx := context.Background()
+	loads := staticLoads{"u1": 1}
+	seen := map[string]bool{}
+	for seed := int64(1); seed <= 50; seed++ {
+		got, err := NewLeastLoaded(loads, seed).Select(ctx, users("u1", "u2", "u3"), 1)
+		require.NoError(t, err)
+		seen[got[0].ID] = true
+	}
+	assert.Equal(t, map[string]bool{"u2": true, "u3": true}, seen)
+}
+
+func TestWeighted(t *testing.T) {
+	ctx := context.Background()
+	loads := staticLoads{"u1": 99, "u2": 0, "u3": 0}
+	picked := map[string]int{}
+	for seed := int64(1); seed <= 200; seed++ {
+		got, err := NewWeighted(loads, seed).Select(ctx, users("u1", "u2", "u3"), 2)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.NotEqual(t, got[0].ID, got[1].ID)
+		for _, u := range got {
+			picked[u.ID]++
+		}
+	}
+	assert.Less(t, picked["u1"], picked["u2"])
+	assert.Less(t, picked["u1"], picked["u3"])
+}
+
+func TestRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	s := NewRoundRobin(&memoryRotation{})
+
+	var got [][]string
+	for range 3 {
+		picked, err := s.Select(ctx, users("u1", "u2", "u3"), 2)
+		require.NoError(t, err)
+		got = append(got, userIDs(picked))
+	}
+	assert.Equal(t, [][]string{{"u1", "u2"}, {"u3", "u1"}, {"u2", "u3"}}, got)
+
+	// u3 was deactivated: the turn passes to the next active member.
+	picked, err := s.Select(ctx, users("u1", "u2"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, userIDs(picked))
+}
+
+func TestWorkingHoursPrefersReviewersAtWork(t *testing.T) {
+	ctx := context.Background()
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	schedules := staticSchedules{
+		"u1": {Timezone: "Europe/Moscow", StartMinute: 9 * 60, EndMinute: 18 * 60, Weekdays: weekdays},
+		"u2": {Timezone: "Europe/Belgrade", StartMinute: 9 * 60, EndMinute: 18 * 60, Weekdays: weekdays},
+		"u3": {Timezone: "Asia/Almaty", StartMinute: 9 * 60, EndMinute: 18 * 60, Weekdays: weekdays},
+	}
+	s := NewWorkingHours(staticLoads{"u2": 5}, schedules, 1).(*workingHours)
+	// Wednesday 19:00 in Moscow: Belgrade is at work, Almaty starts at 04:00 UTC and Moscow at 06:00 UTC.
+	s.now = func() time.Time { return time.Date(2025, 3, 12, 16, 0, 0, 0, time.UTC) }
+
+	picked, err := s.Select(ctx, users("u3", "u1", "u2"), 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3", "u1"}, userIDs(picked))
+
+	// Without a schedule a user counts as working, the load breaks the tie.
+	picked, err = s.Select(ctx, users("u1", "u2", "u4"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u2"}, userIDs(picked))
+}
+
+func TestNew(t *testing.T) {
+	for _, strategy := range Strategies {
+		s, err := New(config.SelectorConfig{Strategy: string(strategy)}, staticLoads{}, &memoryRotation{}, staticSchedules{})
+		require.NoError(t, err)
+		assert.NotNil(t, s)
+	}
+	_, err := New(config.SelectorConfig{Strategy: "unknown"}, staticLoads{}, &memoryRotation{}, staticSchedules{})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestRegistry(t *testing.T) {
+	registry, err := NewRegistry(config.SelectorConfig{Strategy: string(StrategyRandom)}, staticLoads{}, staticSchedules{})
+	require.NoError(t, err)
+
+	assert.IsType(t, &random{}, registry.For("", &memoryRotation{}))
+	assert.IsType(t, &roundRobin{}, registry.For(string(StrategyRoundRobin), &memoryRotation{}))
+	assert.IsType(t, &leastLoaded{}, registry.For(string(StrategyLeastLoaded), &memoryRotation{}))
+	assert.True(t, IsKnown("weighted"))
+	assert.False(t, IsKnown("unknown"))
+
+	_, err = NewRegistry(config.SelectorConfig{Strategy: "unknown"}, staticLoads{}, staticSchedules{})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestSelectTieredPrefersTagOverlap(t *testing.T) {
+	ctx := context.Background()
+	candidates := users("u1", "u2", "u3", "u4")
+	skills := map[string][]model.Skill{
+		"u1": {{Tag: "frontend"}},
+		"u2": {{Tag: "payments", Level: 2}},
+		"u3": {{Tag: "db"}, {Tag: "payments", Level: 5}},
+		"u4": {{Tag: "payments"}},
+	}
+	labels := []string{"payments", "db"}
+
+	tiers := RankByTags(candidates, skills, labels)
+	require.Len(t, tiers, 3)
+	assert.Equal(t, users("u3"), tiers[0])
+	assert.Equal(t, users("u2", "u4"), tiers[1])
+	assert.Equal(t, users("u1"), tiers[2])
+	assert.Equal(t, []string{"db", "payments"}, MatchTags(skills["u3"], labels))
+
+	// inside a tier the strategy still decides: u4 has fewer open reviews than u2
+	leastLoaded := NewLeastLoaded(staticLoads{"u2": 3, "u4": 1}, 1)
+	picked, err := SelectTiered(ctx, leastLoaded, tiers, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, userIDs(picked))
+
+	picked, err = SelectTiered(ctx, leastLoaded, RankByTags(candidates, skills, nil), 2)
+	require.NoError(t, err)
+	assert.Len(t, picked, 2, "without labels everyone is in one tier")
+}
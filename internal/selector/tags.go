@@ -0,0 +1,58 @@
+package selector
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+// MatchTags returns the tags of skills that are among the labels, sorted.
+// Both sides are expected to be normalized with model.NormalizeTag.
+func MatchTags(skills []model.Skill, labels []string) []string {
+	var matched []string
+	for _, skill := range skills {
+		if slices.Contains(labels, skill.Tag) {
+			matched = append(matched, skill.Tag)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// RankByTags splits candidates into tiers by the number of skills matching the labels, the most
+// matches first. Candidates without a match form the last tier. Order inside a tier is kept.
+func RankByTags(candidates []model.User, skills map[string][]model.Skill, labels []string) [][]model.User {
+	byMatches := make(map[int][]model.User)
+	for _, candidate := range candidates {
+		matches := len(MatchTags(skills[candidate.ID], labels))
+		byMatches[matches] = append(byMatches[matches], candidate)
+	}
+	counts := make([]int, 0, len(byMatches))
+	for matches := range byMatches {
+		counts = append(counts, matches)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	tiers := make([][]model.User, len(counts))
+	for i, matches := range counts {
+		tiers[i] = byMatches[matches]
+	}
+	return tiers
+}
+
+// SelectTiered picks up to n candidates, exhausting a tier before moving to the next one.
+func SelectTiered(ctx context.Context, s ReviewerSelector, tiers [][]model.User, n int) ([]model.User, error) {
+	res := make([]model.User, 0, n)
+	for _, tier := range tiers {
+		if len(res) >= n {
+			break
+		}
+		picked, err := s.Select(ctx, tier, n-len(res))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, picked...)
+	}
+	return res, nil
+}
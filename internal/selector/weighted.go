@@ -0,0 +1,55 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type weighted struct {
+	loads LoadCounter
+	rnd   *lockedRand
+}
+
+// NewWeighted picks reviewers at random with probability proportional to 1/(open reviews + 1).
+func NewWeighted(loads LoadCounter, seed int64) ReviewerSelector {
+	return &weighted{loads: loads, rnd: newLockedRand(seed)}
+}
+
+func (s *weighted) Select(ctx context.Context, candidates []model.User, n int) ([]model.User, error) {
+	if len(candidates) == 0 {
+		return []model.User{}, nil
+	}
+	loads, err := s.loads.CountOpenReviews(ctx, userIDs(candidates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+
+	pool := make([]model.User, len(candidates))
+	copy(pool, candidates)
+	weights := make([]float64, len(pool))
+	total := 0.0
+	for i, c := range pool {
+		weights[i] = 1 / float64(loads[c.ID]+1)
+		total += weights[i]
+	}
+
+	res := make([]model.User, 0, min(n, len(pool)))
+	for len(res) < n && len(pool) > 0 {
+		target := s.rnd.Float64() * total
+		idx := len(pool) - 1
+		for i, w := range weights {
+			if target < w {
+				idx = i
+				break
+			}
+			target -= w
+		}
+		res = append(res, pool[idx])
+		total -= weights[idx]
+		pool = append(pool[:idx], pool[idx+1:]...)
+		weights = append(weights[:idx], weights[idx+1:]...)
+	}
+	return res, nil
+}
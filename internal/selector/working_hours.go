@@ -0,0 +1,61 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/workhours"
+)
+
+// ScheduleSource returns working hours of users, users without a schedule are left out.
+type ScheduleSource interface {
+	GetSchedules(ctx context.Context, userIDs []string) (map[string]model.WorkSchedule, error)
+}
+
+type workingHours struct {
+	loads     LoadCounter
+	schedules ScheduleSource
+	rnd       *lockedRand
+	now       func() time.Time
+}
+
+// NewWorkingHours picks reviewers who are at work right now, then the ones whose working hours start soonest.
+// Users without a schedule count as working. Among equally available reviewers the least loaded go first.
+func NewWorkingHours(loads LoadCounter, schedules ScheduleSource, seed int64) ReviewerSelector {
+	return &workingHours{loads: loads, schedules: schedules, rnd: newLockedRand(seed), now: time.Now}
+}
+
+func (s *workingHours) Select(ctx context.Context, candidates []model.User, n int) ([]model.User, error) {
+	if len(candidates) == 0 {
+		return []model.User{}, nil
+	}
+	ids := userIDs(candidates)
+	schedules, err := s.schedules.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working hours: %w", err)
+	}
+	loads, err := s.loads.CountOpenReviews(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+
+	now := s.now()
+	waits := make(map[string]time.Duration, len(candidates))
+	for _, candidate := range candidates {
+		waits[candidate.ID] = workhours.Until(schedules[candidate.ID], now)
+	}
+	res := shuffled(s.rnd, candidates)
+	sort.SliceStable(res, func(i, j int) bool {
+		if waits[res[i].ID] != waits[res[j].ID] {
+			return waits[res[i].ID] < waits[res[j].ID]
+		}
+		return loads[res[i].ID] < loads[res[j].ID]
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res, nil
+}
@@ -0,0 +1,35 @@
+package codehost
+
+import (
+	"context"
+	"errors"
+
+	codeHostRepo "github.com/doverlof/avito_help/internal/client/repo/codehost"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+var (
+	ErrSyncNotFound = errors.New("code host sync not found")
+)
+
+type UseCase interface {
+	GetSync(ctx context.Context, pullRequestID string) (model.CodeHostSync, error)
+}
+
+type useCase struct {
+	repo codeHostRepo.Repo
+}
+
+func New(repo codeHostRepo.Repo) UseCase {
+	return &useCase{
+		repo: repo,
+	}
+}
+
+func (u *useCase) GetSync(ctx context.Context, pullRequestID string) (model.CodeHostSync, error) {
+	sync, err := u.repo.Get(ctx, pullRequestID)
+	if errors.Is(err, codeHostRepo.ErrSyncNotFound) {
+		return model.CodeHostSync{}, ErrSyncNotFound
+	}
+	return sync, err
+}
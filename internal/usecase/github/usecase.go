@@ -0,0 +1,196 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doverlof/avito_help/internal/actor"
+	"github.com/doverlof/avito_help/internal/client/codehost"
+	githubRepo "github.com/doverlof/avito_help/internal/client/repo/github"
+	"github.com/doverlof/avito_help/internal/model"
+	pullRequestUseCase "github.com/doverlof/avito_help/internal/usecase/pull-request"
+)
+
+const pullRequestEvent = "pull_request"
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrMalformedPayload = errors.New("malformed webhook payload")
+	ErrInvalidUser      = errors.New("invalid github user link")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserLinked       = errors.New("user is linked to another github login")
+)
+
+type UseCase interface {
+	HandleWebhook(ctx context.Context, delivery model.GitHubDelivery) (model.GitHubDeliveryResult, error)
+	SetUser(ctx context.Context, user model.GitHubUser) (model.GitHubUser, error)
+	ListUsers(ctx context.Context) ([]model.GitHubUser, error)
+}
+
+// PullRequests is the part of the pull request use case driven by GitHub events.
+type PullRequests interface {
+	Create(ctx context.Context, pullRequest model.CreatePullRequest) (model.PullRequest, error)
+	Merge(ctx context.Context, pullRequestID string, force bool) (model.PullRequest, error)
+	Close(ctx context.Context, pullRequestID string) (model.PullRequest, error)
+	Reopen(ctx context.Context, pullRequestID string) (model.PullRequest, []model.Reassignment, error)
+}
+
+type useCase struct {
+	repo         githubRepo.Repo
+	pullRequests PullRequests
+	secret       string
+}
+
+func New(repo githubRepo.Repo, pullRequests PullRequests, secret string) UseCase {
+	return &useCase{
+		repo:         repo,
+		pullRequests: pullRequests,
+		secret:       secret,
+	}
+}
+
+type pullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// HandleWebhook applies a GitHub pull_request event once per delivery id. A delivery that fails is
+// forgotten again, so that GitHub can redeliver it.
+func (u *useCase) HandleWebhook(ctx context.Context, delivery model.GitHubDelivery) (model.GitHubDeliveryResult, error) {
+	if !u.verify(delivery.Body, delivery.Signature) {
+		return model.GitHubDeliveryResult{}, ErrInvalidSignature
+	}
+	result := model.GitHubDeliveryResult{DeliveryID: delivery.ID}
+	if delivery.Event != pullRequestEvent {
+		result.Status = model.GitHubIgnored
+		result.Reason = fmt.Sprintf("event %q is not handled", delivery.Event)
+		return result, nil
+	}
+	var payload pullRequestPayload
+	if err := json.Unmarshal(delivery.Body, &payload); err != nil {
+		return model.GitHubDeliveryResult{}, fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+	}
+	owner, repo, ok := strings.Cut(payload.Repository.FullName, "/")
+	if !ok || payload.Number <= 0 {
+		return model.GitHubDeliveryResult{}, fmt.Errorf("%w: repository and number are required", ErrMalformedPayload)
+	}
+	result.Action = payload.Action
+	result.PullRequestID = codehost.PullRequestRef{Owner: owner, Repo: repo, Number: payload.Number}.ID()
+
+	claimed, err := u.repo.ClaimDelivery(ctx, delivery, payload.Action)
+	if err != nil {
+		return model.GitHubDeliveryResult{}, err
+	}
+	if !claimed {
+		result.Status = model.GitHubDuplicate
+		return result, nil
+	}
+
+	if payload.Sender.Login != "" {
+		ctx = actor.WithActor(ctx, "github:"+payload.Sender.Login)
+	}
+	result.Status, result.Reason, err = u.apply(ctx, payload, result.PullRequestID)
+	if err != nil {
+		if releaseErr := u.repo.ReleaseDelivery(context.WithoutCancel(ctx), delivery.ID); releaseErr != nil {
+			return model.GitHubDeliveryResult{}, errors.Join(err, releaseErr)
+		}
+		return model.GitHubDeliveryResult{}, err
+	}
+	return result, nil
+}
+
+func (u *useCase) apply(ctx context.Context, payload pullRequestPayload, pullRequestID string) (model.GitHubDeliveryStatus, string, error) {
+	switch payload.Action {
+	case "opened", "ready_for_review":
+		if payload.PullRequest.Draft {
+			return model.GitHubIgnored, "draft pull requests get reviewers when ready for review", nil
+		}
+		authorID, err := u.repo.GetUserID(ctx, strings.ToLower(payload.PullRequest.User.Login))
+		if errors.Is(err, githubRepo.ErrLoginNotFound) {
+			return model.GitHubIgnored, fmt.Sprintf("github login %q is not linked", payload.PullRequest.User.Login), nil
+		}
+		if err != nil {
+			return "", "", err
+		}
+		_, err = u.pullRequests.Create(ctx, model.CreatePullRequest{
+			AuthorID:        authorID,
+			PullRequestID:   pullRequestID,
+			PullRequestName: payload.PullRequest.Title,
+		})
+		if errors.Is(err, pullRequestUseCase.ErrPRExists) {
+			return model.GitHubIgnored, "pull request already exists", nil
+		}
+		return model.GitHubProcessed, "", err
+	case "closed":
+		var err error
+		if payload.PullRequest.Merged {
+			// the merge already happened on GitHub, approvals can't stop it
+			_, err = u.pullRequests.Merge(ctx, pullRequestID, true)
+		} else {
+			_, err = u.pullRequests.Close(ctx, pullRequestID)
+		}
+		if errors.Is(err, pullRequestUseCase.ErrPRNotFound) {
+			return model.GitHubIgnored, "pull request is not tracked", nil
+		}
+		return model.GitHubProcessed, "", err
+	case "reopened":
+		_, _, err := u.pullRequests.Reopen(ctx, pullRequestID)
+		if errors.Is(err, pullRequestUseCase.ErrPRNotFound) {
+			return model.GitHubIgnored, "pull request is not tracked", nil
+		}
+		return model.GitHubProcessed, "", err
+	}
+	return model.GitHubIgnored, fmt.Sprintf("action %q is not handled", payload.Action), nil
+}
+
+// verify checks X-Hub-Signature-256. Without a configured secret every delivery is rejected.
+func (u *useCase) verify(body []byte, signature string) bool {
+	if u.secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(u.secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (u *useCase) SetUser(ctx context.Context, user model.GitHubUser) (model.GitHubUser, error) {
+	user.Login = strings.ToLower(strings.TrimSpace(user.Login))
+	if user.Login == "" || user.UserID == "" {
+		return model.GitHubUser{}, fmt.Errorf("%w: login and user_id are required", ErrInvalidUser)
+	}
+	err := u.repo.SetUser(ctx, user)
+	switch {
+	case errors.Is(err, githubRepo.ErrUserNotFound):
+		return model.GitHubUser{}, ErrUserNotFound
+	case errors.Is(err, githubRepo.ErrUserLinked):
+		return model.GitHubUser{}, ErrUserLinked
+	case err != nil:
+		return model.GitHubUser{}, err
+	}
+	return user, nil
+}
+
+func (u *useCase) ListUsers(ctx context.Context) ([]model.GitHubUser, error) {
+	return u.repo.ListUsers(ctx)
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	githubRepo "github.com/doverlof/avito_help/internal/client/repo/github"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "s3cret"
+
+type memoryRepo struct {
+	logins     map[string]string
+	deliveries map[string]bool
+}
+
+func (m *memoryRepo) SetUser(_ context.Context, user model.GitHubUser) error {
+	m.logins[user.Login] = user.UserID
+	return nil
+}
+
+func (m *memoryRepo) ListUsers(context.Context) ([]model.GitHubUser, error) { return nil, nil }
+
+func (m *memoryRepo) GetUserID(_ context.Context, login string) (string, error) {
+	userID, ok := m.logins[login]
+	if !ok {
+		return "", githubRepo.ErrLoginNotFound
+	}
+	return userID, nil
+}
+
+func (m *memoryRepo) ClaimDelivery(_ context.Context, delivery model.GitHubDelivery, _ string) (bool, error) {
+	if m.deliveries[delivery.ID] {
+		return false, nil
+	}
+	m.deliveries[delivery.ID] = true
+	return true, nil
+}
+
+func (m *memoryRepo) ReleaseDelivery(ctx context.Context, deliveryID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(m.deliveries, deliveryID)
+	return nil
+}
+
+type recordedPullRequests struct {
+	calls []string
+	err   error
+}
+
+func (r *recordedPullRequests) Create(_ context.Context, pr model.CreatePullRequest) (model.PullRequest, error) {
+	r.calls = append(r.calls, "create "+pr.PullRequestID+" "+pr.AuthorID)
+	return model.PullRequest{}, r.err
+}
+
+func (r *recordedPullRequests) Merge(_ context.Context, id string, force bool) (model.PullRequest, error) {
+	if force {
+		r.calls = append(r.calls, "merge "+id)
+	}
+	return model.PullRequest{}, r.err
+}
+
+func (r *recordedPullRequests) Close(_ context.Context, id string) (model.PullRequest, error) {
+	r.calls = append(r.calls, "close "+id)
+	return model.PullRequest{}, r.err
+}
+
+func (r *recordedPullRequests) Reopen(_ context.Context, id string) (model.PullRequest, []model.Reassignment, error) {
+	r.calls = append(r.calls, "reopen "+id)
+	return model.PullRequest{}, nil, r.err
+}
+
+func newTestUseCase() (*useCase, *memoryRepo, *recordedPullRequests) {
+	repo := &memoryRepo{
+		logins:     map[string]string{"octocat": "u1"},
+		deliveries: map[string]bool{},
+	}
+	pullRequests := &recordedPullRequests{}
+	return New(repo, pullRequests, testSecret).(*useCase), repo, pullRequests
+}
+
+func signedDelivery(id, body string) model.GitHubDelivery {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return model.GitHubDelivery{
+		ID:        id,
+		Event:     pullRequestEvent,
+		Signature: "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		Body:      []byte(body),
+	}
+}
+
+const openedBody = `{"action":"opened","number":7,"pull_request":{"title":"Add search","user":{"login":"OctoCat"}},` +
+	`"repository":{"full_name":"acme/api"},"sender":{"login":"octocat"}}`
+
+func TestHandleWebhookRejectsBadSignature(t *testing.T) {
+	u, _, pullRequests := newTestUseCase()
+
+	delivery := signedDelivery("d1", openedBody)
+	delivery.Body = []byte(openedBody + " ")
+	_, err := u.HandleWebhook(context.Background(), delivery)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	u.secret = ""
+	_, err = u.HandleWebhook(context.Background(), signedDelivery("d1", openedBody))
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	require.Empty(t, pullRequests.calls)
+}
+
+func TestHandleWebhookIsIdempotent(t *testing.T) {
+	u, _, pullRequests := newTestUseCase()
+
+	result, err := u.HandleWebhook(context.Background(), signedDelivery("d1", openedBody))
+	require.NoError(t, err)
+	require.Equal(t, model.GitHubProcessed, result.Status)
+	require.Equal(t, "acme/api#7", result.PullRequestID)
+
+	result, err = u.HandleWebhook(context.Background(), signedDelivery("d1", openedBody))
+	require.NoError(t, err)
+	require.Equal(t, model.GitHubDuplicate, result.Status)
+	require.Equal(t, []string{"create acme/api#7 u1"}, pullRequests.calls)
+}
+
+func TestHandleWebhookMapsActions(t *testing.T) {
+	u, _, pullRequests := newTestUseCase()
+
+	bodies := []string{
+		`{"action":"closed","number":7,"pull_request":{"merged":true},"repository":{"full_name":"acme/api"}}`,
+		`{"action":"closed","number":8,"pull_request":{"merged":false},"repository":{"full_name":"acme/api"}}`,
+		`{"action":"reopened","number":8,"repository":{"full_name":"acme/api"}}`,
+		`{"action":"labeled","number":8,"repository":{"full_name":"acme/api"}}`,
+	}
+	for i, body := range bodies {
+		_, err := u.HandleWebhook(context.Background(), signedDelivery(string(rune('a'+i)), body))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"merge acme/api#7", "close acme/api#8", "reopen acme/api#8"}, pullRequests.calls)
+}
+
+func TestHandleWebhookReleasesFailedDelivery(t *testing.T) {
+	u, repo, pullRequests := newTestUseCase()
+	pullRequests.err = errors.New("database is down")
+
+	_, err := u.HandleWebhook(context.Background(), signedDelivery("d1", openedBody))
+	require.Error(t, err)
+	require.False(t, repo.deliveries["d1"])
+}
+
+func TestHandleWebhookReleasesDeliveryOfCanceledRequest(t *testing.T) {
+	u, repo, pullRequests := newTestUseCase()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pullRequests.err = context.Canceled
+
+	_, err := u.HandleWebhook(ctx, signedDelivery("d1", openedBody))
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, repo.deliveries["d1"])
+}
+
+func TestHandleWebhookIgnoresUnknownAuthor(t *testing.T) {
+	u, _, pullRequests := newTestUseCase()
+	body := `{"action":"opened","number":9,"pull_request":{"user":{"login":"stranger"}},"repository":{"full_name":"acme/api"}}`
+
+	result, err := u.HandleWebhook(context.Background(), signedDelivery("d1", body))
+	require.NoError(t, err)
+	require.Equal(t, model.GitHubIgnored, result.Status)
+	require.Empty(t, pullRequests.calls)
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	healthRepo "github.com/doverlof/avito_help/internal/client/repo/health"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+type UseCase interface {
+	// Ready checks Postgres, the schema version and the background workers.
+	Ready(ctx context.Context) model.Readiness
+}
+
+type useCase struct {
+	repo       healthRepo.Repo
+	components *health.Components
+	timeout    time.Duration
+}
+
+func New(repo healthRepo.Repo, components *health.Components, timeout time.Duration) UseCase {
+	return &useCase{
+		repo:       repo,
+		components: components,
+		timeout:    timeout,
+	}
+}
+
+func (u *useCase) Ready(ctx context.Context) model.Readiness {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	checks := []model.HealthCheck{u.checkPostgres(ctx), u.checkSchema(ctx)}
+	for _, status := range u.components.Statuses() {
+		checks = append(checks, checkComponent(status))
+	}
+	res := model.Readiness{Ready: true, Checks: checks}
+	for _, check := range checks {
+		if check.Status == model.CheckFail {
+			res.Ready = false
+		}
+	}
+	return res
+}
+
+func (u *useCase) checkPostgres(ctx context.Context) model.HealthCheck {
+	check := model.HealthCheck{Name: "postgres", Status: model.CheckOK}
+	if err := u.repo.Ping(ctx); err != nil {
+		check.Status = model.CheckFail
+		check.Message = err.Error()
+	}
+	return check
+}
+
+func (u *useCase) checkSchema(ctx context.Context) model.HealthCheck {
+	check := model.HealthCheck{Name: "migrations", Status: model.CheckOK}
+	version, err := u.repo.GetSchemaVersion(ctx)
+	switch {
+	case err != nil:
+		check.Status = model.CheckFail
+		check.Message = err.Error()
+	case version < healthRepo.SchemaVersion:
+		// A newer schema is fine, it is what a rolling deploy looks like for the old instances.
+		check.Status = model.CheckFail
+		check.Message = fmt.Sprintf("schema version %d, expected at least %d", version, healthRepo.SchemaVersion)
+	default:
+		check.Message = fmt.Sprintf("schema version %d", version)
+	}
+	return check
+}
+
+// checkComponent fails only for a stopped worker, an error of its last tick is only reported.
+func checkComponent(status health.Status) model.HealthCheck {
+	check := model.HealthCheck{
+		Name:      status.Name,
+		Status:    model.CheckOK,
+		Message:   status.LastError,
+		LastRunAt: status.LastRunAt,
+	}
+	switch status.State {
+	case health.StateDisabled:
+		check.Status = model.CheckDisabled
+	case health.StateStopped:
+		check.Status = model.CheckFail
+		check.Message = "worker is not running"
+	}
+	return check
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	healthRepo "github.com/doverlof/avito_help/internal/client/repo/health"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	pingErr error
+	version int
+}
+
+func (f *fakeRepo) Ping(context.Context) error { return f.pingErr }
+
+func (f *fakeRepo) GetSchemaVersion(context.Context) (int, error) { return f.version, nil }
+
+func checkStatuses(readiness model.Readiness) map[string]model.CheckStatus {
+	res := make(map[string]model.CheckStatus)
+	for _, check := range readiness.Checks {
+		res[check.Name] = check.Status
+	}
+	return res
+}
+
+func TestReady(t *testing.T) {
+	components := health.NewComponents()
+	worker := components.Add("worker")
+	worker.Started()
+	worker.Ran(errors.New("target is down"))
+	components.Add("syncer").Disable()
+
+	readiness := New(&fakeRepo{version: healthRepo.SchemaVersion}, components, time.Second).Ready(context.Background())
+
+	require.True(t, readiness.Ready)
+	assert.Equal(t, map[string]model.CheckStatus{
+		"postgres":   model.CheckOK,
+		"migrations": model.CheckOK,
+		"worker":     model.CheckOK,
+		"syncer":     model.CheckDisabled,
+	}, checkStatuses(readiness))
+	assert.Equal(t, "target is down", readiness.Checks[2].Message)
+	assert.False(t, readiness.Checks[2].LastRunAt.IsZero())
+}
+
+func TestNotReady(t *testing.T) {
+	components := health.NewComponents()
+	worker := components.Add("worker")
+	worker.Started()
+	worker.Stopped()
+
+	readiness := New(&fakeRepo{pingErr: errors.New("connection refused"), version: healthRepo.SchemaVersion - 1},
+		components, time.Second).Ready(context.Background())
+
+	require.False(t, readiness.Ready)
+	assert.Equal(t, map[string]model.CheckStatus{
+		"postgres":   model.CheckFail,
+		"migrations": model.CheckFail,
+		"worker":     model.CheckFail,
+	}, checkStatuses(readiness))
+}
+
+func TestSchemaAhead(t *testing.T) {
+	readiness := New(&fakeRepo{version: healthRepo.SchemaVersion + 1}, health.NewComponents(), time.Second).
+		Ready(context.Background())
+
+	require.True(t, readiness.Ready)
+	assert.Equal(t, model.CheckOK, checkStatuses(readiness)["migrations"])
+}
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	entries, err := os.ReadDir("../../../migrations")
+	require.NoError(t, err)
+	numbered := regexp.MustCompile(`^(\d+)_.*\.sql$`)
+	latest := 0
+	for _, entry := range entries {
+		if match := numbered.FindStringSubmatch(entry.Name()); match != nil {
+			version, err := strconv.Atoi(match[1])
+			require.NoError(t, err)
+			latest = max(latest, version)
+		}
+	}
+	assert.Equal(t, latest, healthRepo.SchemaVersion, "bump SchemaVersion together with a new migration")
+}
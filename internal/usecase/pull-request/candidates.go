@@ -0,0 +1,232 @@
+package pull_request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/client/codehost"
+	rotationPkg "github.com/doverlof/avito_help/internal/client/repo/rotation"
+	teamPkg "github.com/doverlof/avito_help/internal/client/repo/team"
+	userPkg "github.com/doverlof/avito_help/internal/client/repo/user"
+	"github.com/doverlof/avito_help/internal/codeowners"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/selector"
+	"github.com/doverlof/avito_help/internal/workhours"
+)
+
+// candidatePool holds the active teammates of one author and the members of the team's fallback teams.
+type candidatePool struct {
+	author        model.User
+	settings      model.TeamSettings
+	home          []model.User
+	fallbackTeams []string
+	fallback      map[string][]model.User
+	turns         *rotationPkg.Turns
+}
+
+func emptyCandidatePool(authorID string, turns *rotationPkg.Turns) *candidatePool {
+	return &candidatePool{
+		author:   model.User{ID: authorID},
+		settings: model.DefaultTeamSettings(""),
+		fallback: make(map[string][]model.User),
+		turns:    turns,
+	}
+}
+
+func (u *useCase) getCandidatePool(ctx context.Context, authorID string, turns *rotationPkg.Turns) (*candidatePool, error) {
+	author, err := u.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		if errors.Is(err, userPkg.ErrUserNotFound) {
+			return nil, ErrTeamOrAuthorNotFound
+		}
+		return nil, err
+	}
+	settings, err := u.getTeamSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	home, err := u.userRepo.GetReviewersByAuthorID(ctx, authorID, settings.AbsenceLookahead())
+	if err != nil {
+		fmt.Println(err)
+		if errors.Is(err, userPkg.ErrTeamOrAuthorNotFound) {
+			return nil, ErrTeamOrAuthorNotFound
+		}
+		return nil, err
+	}
+	fallbackTeams, err := u.teamRepo.GetFallbacks(ctx, author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	return &candidatePool{
+		author:        author,
+		settings:      settings,
+		home:          home,
+		fallbackTeams: fallbackTeams,
+		fallback:      make(map[string][]model.User),
+		turns:         turns,
+	}, nil
+}
+
+// pickReviewers selects up to n reviewers who are not in excludedIDs, fallback teams fill the rest.
+func (u *useCase) pickReviewers(ctx context.Context, pool *candidatePool, labels []string, excludedIDs []string, n int) ([]model.Reviewer, error) {
+	reviewerSelector := u.selectors.For(pool.settings.Strategy, pool.turns)
+	excluded := append(slices.Clone(excludedIDs), pool.author.ID)
+
+	picked, skills, err := u.selectByLabels(ctx, reviewerSelector, excludeUsers(pool.home, excluded), labels, n)
+	if err != nil {
+		return nil, err
+	}
+	res := toReviewers(picked, false, skills, labels)
+	for _, team := range pool.fallbackTeams {
+		if len(res) >= n {
+			break
+		}
+		users, ok := pool.fallback[team]
+		if !ok {
+			users, err = u.userRepo.GetActiveByTeam(ctx, team, pool.settings.AbsenceLookahead())
+			if err != nil {
+				return nil, err
+			}
+			pool.fallback[team] = users
+		}
+		for _, reviewer := range res {
+			excluded = append(excluded, reviewer.ID)
+		}
+		picked, skills, err = u.selectByLabels(ctx, reviewerSelector, excludeUsers(users, excluded), labels, n-len(res))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, toReviewers(picked, true, skills, labels)...)
+	}
+	if err = u.setDueAt(ctx, pool.settings, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// pickCodeOwners selects up to max_reviewers active owners of the changed files from the team's CODEOWNERS.
+func (u *useCase) pickCodeOwners(ctx context.Context, pool *candidatePool, pullRequest model.CreatePullRequest) ([]model.Reviewer, error) {
+	if len(pullRequest.ChangedFiles) == 0 || pool.author.TeamName == "" {
+		return nil, nil
+	}
+	repository := pullRequest.Repository
+	if repository == "" {
+		ref, ok := codehost.ParsePullRequestID(pullRequest.PullRequestID)
+		if !ok {
+			return nil, nil
+		}
+		repository = ref.Owner + "/" + ref.Repo
+	}
+	file, err := u.teamRepo.GetCodeOwners(ctx, pool.author.TeamName, repository)
+	if errors.Is(err, teamPkg.ErrCodeOwnersNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rules, err := codeowners.Parse(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("stored CODEOWNERS of %s for %s: %w", pool.author.TeamName, repository, err)
+	}
+	logins, teams := codeowners.SplitOwners(rules.OwnersOf(pullRequest.ChangedFiles))
+	owners, err := u.userRepo.GetActiveByCodeOwners(ctx, logins, teams, pool.settings.AbsenceLookahead())
+	if err != nil {
+		return nil, err
+	}
+	picked, skills, err := u.selectByLabels(ctx, u.selectors.For(pool.settings.Strategy, pool.turns),
+		excludeUsers(owners, []string{pool.author.ID}), pullRequest.Labels, pool.settings.MaxReviewers)
+	if err != nil {
+		return nil, err
+	}
+	res := toReviewers(picked, false, skills, pullRequest.Labels)
+	for i := range res {
+		res[i].IsCodeOwner = true
+	}
+	if err = u.setDueAt(ctx, pool.settings, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// setDueAt sets the SLA deadline of the reviewers in their working hours, without an SLA it stays zero.
+func (u *useCase) setDueAt(ctx context.Context, settings model.TeamSettings, reviewers []model.Reviewer) error {
+	if settings.ReviewSLAHours == 0 || len(reviewers) == 0 {
+		return nil
+	}
+	schedules, err := u.userRepo.GetSchedules(ctx, reviewerIDs(reviewers))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range reviewers {
+		reviewers[i].DueAt = workhours.Add(schedules[reviewers[i].ID], now, settings.ReviewSLA())
+	}
+	return nil
+}
+
+// selectByLabels picks up to n candidates, those matching more labels first, and returns the loaded skills.
+func (u *useCase) selectByLabels(ctx context.Context, s selector.ReviewerSelector, candidates []model.User, labels []string, n int) ([]model.User, map[string][]model.Skill, error) {
+	if len(labels) == 0 || len(candidates) == 0 {
+		picked, err := s.Select(ctx, candidates, n)
+		return picked, nil, err
+	}
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.ID
+	}
+	skills, err := u.userRepo.GetSkills(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	picked, err := selector.SelectTiered(ctx, s, selector.RankByTags(candidates, skills, labels), n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return picked, skills, nil
+}
+
+// normalizeLabels normalizes labels like skill tags, dropping empty and repeated ones.
+func normalizeLabels(labels []string) []string {
+	res := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = model.NormalizeTag(label)
+		if label != "" && !slices.Contains(res, label) {
+			res = append(res, label)
+		}
+	}
+	return res
+}
+
+func reviewerIDs(reviewers []model.Reviewer) []string {
+	ids := make([]string, len(reviewers))
+	for i, reviewer := range reviewers {
+		ids[i] = reviewer.ID
+	}
+	return ids
+}
+
+func toReviewers(users []model.User, isFallback bool, skills map[string][]model.Skill, labels []string) []model.Reviewer {
+	res := make([]model.Reviewer, len(users))
+	for i, user := range users {
+		res[i] = model.Reviewer{
+			ID:          user.ID,
+			TeamName:    user.TeamName,
+			IsFallback:  isFallback,
+			MatchedTags: selector.MatchTags(skills[user.ID], labels),
+		}
+	}
+	return res
+}
+
+func excludeUsers(users []model.User, excludedIDs []string) []model.User {
+	res := make([]model.User, 0, len(users))
+	for _, user := range users {
+		if !slices.Contains(excludedIDs, user.ID) {
+			res = append(res, user)
+		}
+	}
+	return res
+}
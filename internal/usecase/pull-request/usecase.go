@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"slices"
-	"time"
 
 	pullRequestPkg "github.com/doverlof/avito_help/internal/client/repo/pull-request"
+	rotationPkg "github.com/doverlof/avito_help/internal/client/repo/rotation"
+	teamPkg "github.com/doverlof/avito_help/internal/client/repo/team"
 	userPkg "github.com/doverlof/avito_help/internal/client/repo/user"
 	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/selector"
 )
 
 var (
@@ -18,83 +19,346 @@ var (
 	ErrPRNotFound           = errors.New("pull request not found")
 	ErrDontHaveReviewers    = errors.New("dont have reviewers")
 	ErrPRAlreadyMerged      = errors.New("pull request already merged")
+	ErrPRClosed             = errors.New("pull request is closed")
+	ErrNotEnoughApprovals   = errors.New("not enough approvals to merge")
+	ErrInvalidVerdict       = errors.New("invalid review verdict")
 	ErrNotAssigned          = errors.New("reviewer is not assigned to this PR")
 	ErrTeamOrAuthorNotFound = errors.New("team or author not found")
+	ErrNotEnoughReviewers   = errors.New("not enough reviewer candidates")
+	ErrAuthorInactive       = errors.New("inactive author can't open pull requests")
+	ErrConcurrentChange     = errors.New("reviewers were changed concurrently, try again")
 )
 
 type UseCase interface {
-	Create(ctx context.Context, pullRequest model.CreatePullRequest) error
-	Merge(ctx context.Context, pullRequestID string) (model.PullRequest, error)
-	GetByReviewer(ctx context.Context, userID string) ([]model.PullRequest, error)
+	Create(ctx context.Context, pullRequest model.CreatePullRequest) (model.PullRequest, error)
+	Merge(ctx context.Context, pullRequestID string, force bool) (model.PullRequest, error)
+	Review(ctx context.Context, review model.Review) (model.PullRequest, error)
+	Close(ctx context.Context, pullRequestID string) (model.PullRequest, error)
+	Reopen(ctx context.Context, pullRequestID string) (model.PullRequest, []model.Reassignment, error)
+	GetByReviewer(ctx context.Context, userID string, includeClosed bool) ([]model.PullRequest, error)
+	GetHistory(ctx context.Context, pullRequestID string) ([]model.PullRequestEvent, error)
+	GetOverdue(ctx context.Context, filter model.OverdueFilter) ([]model.OverdueReview, error)
 	Reassign(ctx context.Context, pullRequestID, oldReviewerID string) (model.PullRequest, string, error)
+	Escalate(ctx context.Context, pullRequestID, reviewerID string) (model.PullRequest, string, error)
+	DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, fallbackTeams []string) ([]model.Reassignment, error)
+}
+
+// Metrics counts changes of pull requests, see metrics.PullRequests.
+type Metrics interface {
+	Created()
+	Merged()
+	Reassigned(reason string)
+	NoCandidate()
 }
 
 type useCase struct {
 	pullRequestRepo pullRequestPkg.Repo
 	userRepo        userPkg.Repo
+	teamRepo        teamPkg.Repo
+	rotationRepo    rotationPkg.Repo
+	selectors       selector.Resolver
+	metrics         Metrics
 }
 
-func New(repo pullRequestPkg.Repo, userRepo userPkg.Repo) UseCase {
+func New(repo pullRequestPkg.Repo, userRepo userPkg.Repo, teamRepo teamPkg.Repo, rotationRepo rotationPkg.Repo, selectors selector.Resolver, metrics Metrics) UseCase {
 	return &useCase{
 		pullRequestRepo: repo,
 		userRepo:        userRepo,
+		teamRepo:        teamRepo,
+		rotationRepo:    rotationRepo,
+		selectors:       selectors,
+		metrics:         metrics,
 	}
 }
 
-func (u *useCase) Create(ctx context.Context, pullRequest model.CreatePullRequest) error {
-	allAvailable, err := u.userRepo.GetReviewersByAuthorID(ctx, pullRequest.AuthorID)
-	if err != nil {
-		fmt.Println(err)
-		if errors.Is(err, userPkg.ErrTeamOrAuthorNotFound) {
-			return ErrTeamOrAuthorNotFound
+// maxPlanAttempts bounds how many times an outdated assignment is planned again.
+const maxPlanAttempts = 3
+
+// withTurns runs plan with fresh rotation turns until the write stops finding it outdated.
+func (u *useCase) withTurns(plan func(turns *rotationPkg.Turns) error) error {
+	var err error
+	for range maxPlanAttempts {
+		err = plan(rotationPkg.NewTurns(u.rotationRepo))
+		if !errors.Is(err, rotationPkg.ErrCursorMoved) && !errors.Is(err, pullRequestPkg.ErrPlanOutdated) {
+			return err
 		}
+	}
+	return fmt.Errorf("%w: %w", ErrConcurrentChange, err)
+}
+
+func (u *useCase) Create(ctx context.Context, pullRequest model.CreatePullRequest) (model.PullRequest, error) {
+	pullRequest.Labels = normalizeLabels(pullRequest.Labels)
+	var created model.PullRequest
+	err := u.withTurns(func(turns *rotationPkg.Turns) error {
+		var err error
+		created, err = u.create(ctx, pullRequest, turns)
 		return err
+	})
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	u.metrics.Created()
+	return created, nil
+}
+
+func (u *useCase) create(ctx context.Context, pullRequest model.CreatePullRequest, turns *rotationPkg.Turns) (model.PullRequest, error) {
+	pool, err := u.getCandidatePool(ctx, pullRequest.AuthorID, turns)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if !pool.author.IsActive && !pool.settings.AllowInactiveAuthor {
+		return model.PullRequest{}, ErrAuthorInactive
 	}
-	if len(allAvailable) == 0 {
-		return ErrTeamOrAuthorNotFound
+
+	reviewers, err := u.pickCodeOwners(ctx, pool, pullRequest)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if len(reviewers) < pool.settings.MaxReviewers {
+		rest, err := u.pickReviewers(ctx, pool, pullRequest.Labels, reviewerIDs(reviewers), pool.settings.MaxReviewers-len(reviewers))
+		if err != nil {
+			return model.PullRequest{}, err
+		}
+		reviewers = append(reviewers, rest...)
+	}
+	if len(reviewers) < pool.settings.MinReviewers {
+		return model.PullRequest{}, ErrNotEnoughReviewers
 	}
-	reviewers := pickReviewer(allAvailable)
 
 	//Create pr
-	err = u.pullRequestRepo.Create(ctx, pullRequest, reviewers)
+	created, err := u.pullRequestRepo.Create(ctx, pullRequest, reviewers, turns.Moves())
 	if err != nil {
 		fmt.Println(err)
 		if errors.Is(err, pullRequestPkg.ErrPRExists) {
-			return ErrPRExists
+			return model.PullRequest{}, ErrPRExists
 		}
 		if errors.Is(err, pullRequestPkg.ErrDontHaveReviewer) {
-			return ErrTeamOrAuthorNotFound
+			return model.PullRequest{}, ErrTeamOrAuthorNotFound
 		}
+		return model.PullRequest{}, err
+	}
+	return created, nil
+}
+
+func (u *useCase) getTeamSettings(ctx context.Context, teamName string) (model.TeamSettings, error) {
+	if teamName == "" {
+		return model.TeamSettings{}, ErrTeamOrAuthorNotFound
+	}
+	settings, err := u.teamRepo.GetSettings(ctx, teamName)
+	if errors.Is(err, teamPkg.ErrTeamNotFound) {
+		return model.TeamSettings{}, ErrTeamOrAuthorNotFound
+	}
+	return settings, err
+}
+
+// Merge merges the pull request once the author's team required approvals are collected.
+// force skips the approval check.
+func (u *useCase) Merge(ctx context.Context, pullRequestID string, force bool) (model.PullRequest, error) {
+	pullRequest, err := u.pullRequestRepo.GetByID(ctx, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, mapStatusError(err)
+	}
+	requiredApprovals := 0
+	if !force {
+		requiredApprovals, err = u.getRequiredApprovals(ctx, pullRequest.AuthorID)
+		if err != nil {
+			return model.PullRequest{}, err
+		}
+	}
+	merged, err := u.pullRequestRepo.Merge(ctx, pullRequestID, requiredApprovals)
+	if err != nil {
+		return model.PullRequest{}, mapStatusError(err)
+	}
+	if pullRequest.Status != model.StatusMerge {
+		u.metrics.Merged()
+	}
+	return merged, nil
+}
+
+// getRequiredApprovals returns the approvals the author's team requires, authors without a team need none.
+func (u *useCase) getRequiredApprovals(ctx context.Context, authorID string) (int, error) {
+	author, err := u.userRepo.GetByID(ctx, authorID)
+	if errors.Is(err, userPkg.ErrUserNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	settings, err := u.getTeamSettings(ctx, author.TeamName)
+	if errors.Is(err, ErrTeamOrAuthorNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return settings.RequiredApprovals, nil
+}
+
+func (u *useCase) Review(ctx context.Context, review model.Review) (model.PullRequest, error) {
+	if !review.Verdict.IsValid() {
+		return model.PullRequest{}, fmt.Errorf("%w: %q", ErrInvalidVerdict, review.Verdict)
+	}
+	pullRequest, err := u.pullRequestRepo.AddReview(ctx, review)
+	if err != nil {
+		return model.PullRequest{}, mapStatusError(err)
+	}
+	return pullRequest, nil
+}
+
+func (u *useCase) Close(ctx context.Context, pullRequestID string) (model.PullRequest, error) {
+	pullRequest, err := u.pullRequestRepo.Close(ctx, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, mapStatusError(err)
+	}
+	return pullRequest, nil
+}
+
+// Reopen moves a CLOSED pull request back to OPEN and replaces reviewers deactivated while it was closed.
+func (u *useCase) Reopen(ctx context.Context, pullRequestID string) (model.PullRequest, []model.Reassignment, error) {
+	var (
+		pullRequest   model.PullRequest
+		reassignments []model.Reassignment
+	)
+	err := u.withTurns(func(turns *rotationPkg.Turns) error {
+		var err error
+		pullRequest, reassignments, err = u.reopen(ctx, pullRequestID, turns)
 		return err
+	})
+	if err != nil {
+		return model.PullRequest{}, nil, err
+	}
+	u.countReassignments(reassignments, model.ReasonReviewerInactive)
+	return pullRequest, reassignments, nil
+}
+
+func (u *useCase) reopen(ctx context.Context, pullRequestID string, turns *rotationPkg.Turns) (model.PullRequest, []model.Reassignment, error) {
+	pullRequest, err := u.pullRequestRepo.GetByID(ctx, pullRequestID)
+	if err != nil {
+		return model.PullRequest{}, nil, mapStatusError(err)
+	}
+	switch pullRequest.Status {
+	case model.StatusOpen:
+		return pullRequest, []model.Reassignment{}, nil
+	case model.StatusMerge:
+		return model.PullRequest{}, nil, ErrPRAlreadyMerged
+	}
+
+	pool, err := u.getCandidatePool(ctx, pullRequest.AuthorID, turns)
+	if errors.Is(err, ErrTeamOrAuthorNotFound) {
+		pool, err = emptyCandidatePool(pullRequest.AuthorID, turns), nil
+	}
+	if err != nil {
+		return model.PullRequest{}, nil, err
+	}
+	reassignments := make([]model.Reassignment, 0)
+	kept := make([]model.Reviewer, 0, len(pullRequest.Reviewers))
+	excluded := slices.Clone(pullRequest.ReviewerIDs)
+	for _, reviewer := range pullRequest.Reviewers {
+		if reviewer.IsActive {
+			kept = append(kept, model.Reviewer{ID: reviewer.ID})
+			continue
+		}
+		reassignment := model.Reassignment{
+			PullRequestID: pullRequestID,
+			OldReviewerID: reviewer.ID,
+		}
+		picked, err := u.pickReviewers(ctx, pool, pullRequest.Labels, excluded, 1)
+		if err != nil {
+			return model.PullRequest{}, nil, err
+		}
+		if len(picked) > 0 {
+			reassignment.NewReviewerID = picked[0].ID
+			reassignment.NewReviewerTeam = picked[0].TeamName
+			reassignment.IsFallback = picked[0].IsFallback
+			reassignment.MatchedTags = picked[0].MatchedTags
+			reassignment.DueAt = picked[0].DueAt
+			excluded = append(excluded, picked[0].ID)
+		} else {
+			kept = append(kept, model.Reviewer{ID: reviewer.ID})
+		}
+		reassignments = append(reassignments, reassignment)
 	}
-	return nil
+	// The kept reviewers start over, the SLA counts from the reopening.
+	if err = u.setDueAt(ctx, pool.settings, kept); err != nil {
+		return model.PullRequest{}, nil, err
+	}
+
+	pullRequest, err = u.pullRequestRepo.Reopen(ctx, pullRequestID, kept, reassignments, turns.Moves())
+	if err != nil {
+		return model.PullRequest{}, nil, mapStatusError(err)
+	}
+	return pullRequest, reassignments, nil
 }
 
-func pickReviewer(all []model.User) []model.User {
-	n := len(all)
-	if n <= 2 {
-		return all
+// countReassignments counts the reassignments that found a new reviewer.
+func (u *useCase) countReassignments(reassignments []model.Reassignment, reason string) {
+	for _, reassignment := range reassignments {
+		if reassignment.NewReviewerID != "" {
+			u.metrics.Reassigned(reason)
+		}
 	}
+}
 
-	rand.Seed(time.Now().UnixNano())
-	perm := rand.Perm(n)
+func mapStatusError(err error) error {
+	switch {
+	case errors.Is(err, pullRequestPkg.ErrPRNotFound):
+		return ErrPRNotFound
+	case errors.Is(err, pullRequestPkg.ErrPRMerged):
+		return ErrPRAlreadyMerged
+	case errors.Is(err, pullRequestPkg.ErrPRClosed):
+		return ErrPRClosed
+	case errors.Is(err, pullRequestPkg.ErrNotAssigned):
+		return ErrNotAssigned
+	case errors.Is(err, pullRequestPkg.ErrNotApproved):
+		return ErrNotEnoughApprovals
+	}
+	return err
+}
 
-	return []model.User{all[perm[0]], all[perm[1]]}
+func (u *useCase) GetByReviewer(ctx context.Context, userID string, includeClosed bool) ([]model.PullRequest, error) {
+	return u.pullRequestRepo.GetByReviewer(ctx, userID, includeClosed)
 }
 
-func (u *useCase) Merge(ctx context.Context, pullRequestID string) (model.PullRequest, error) {
-	pullRequest, err := u.pullRequestRepo.Merge(ctx, pullRequestID)
-	if errors.Is(err, pullRequestPkg.ErrPRNotFound) {
-		return model.PullRequest{}, ErrPRExists
+func (u *useCase) GetHistory(ctx context.Context, pullRequestID string) ([]model.PullRequestEvent, error) {
+	events, err := u.pullRequestRepo.GetHistory(ctx, pullRequestID)
+	if err != nil {
+		return nil, mapStatusError(err)
 	}
-	return pullRequest, err
+	return events, nil
 }
 
-func (u *useCase) GetByReviewer(ctx context.Context, userID string) ([]model.PullRequest, error) {
-	return u.pullRequestRepo.GetByReviewer(ctx, userID)
+// GetOverdue returns reviews that didn't start within the SLA of the author's team, see TeamSettings.ReviewSLAHours.
+func (u *useCase) GetOverdue(ctx context.Context, filter model.OverdueFilter) ([]model.OverdueReview, error) {
+	return u.pullRequestRepo.GetOverdue(ctx, filter)
 }
 
 func (u *useCase) Reassign(ctx context.Context, pullRequestID, oldReviewerID string) (model.PullRequest, string, error) {
+	return u.reassign(ctx, pullRequestID, oldReviewerID, model.ReasonManual)
+}
+
+// Escalate hands a stale review over to another candidate the same way Reassign does.
+func (u *useCase) Escalate(ctx context.Context, pullRequestID, reviewerID string) (model.PullRequest, string, error) {
+	return u.reassign(ctx, pullRequestID, reviewerID, model.ReasonEscalated)
+}
+
+func (u *useCase) reassign(ctx context.Context, pullRequestID, oldReviewerID, reason string) (model.PullRequest, string, error) {
+	var (
+		pullRequest   model.PullRequest
+		newReviewerID string
+	)
+	err := u.withTurns(func(turns *rotationPkg.Turns) error {
+		var err error
+		pullRequest, newReviewerID, err = u.changeReviewer(ctx, pullRequestID, oldReviewerID, reason, turns)
+		return err
+	})
+	if err != nil {
+		return model.PullRequest{}, "", err
+	}
+	u.metrics.Reassigned(reason)
+	return pullRequest, newReviewerID, nil
+}
+
+func (u *useCase) changeReviewer(ctx context.Context, pullRequestID, oldReviewerID, reason string, turns *rotationPkg.Turns) (model.PullRequest, string, error) {
 	pullRequest, err := u.pullRequestRepo.GetByID(ctx, pullRequestID)
 	if err != nil {
 		if errors.Is(err, pullRequestPkg.ErrPRNotFound) {
@@ -102,34 +366,106 @@ func (u *useCase) Reassign(ctx context.Context, pullRequestID, oldReviewerID str
 		}
 		return model.PullRequest{}, "", err
 	}
-	if pullRequest.Status == model.StatusMerge {
+	switch pullRequest.Status {
+	case model.StatusMerge:
 		return model.PullRequest{}, "", ErrPRAlreadyMerged
+	case model.StatusClosed:
+		return model.PullRequest{}, "", ErrPRClosed
 	}
 	if !slices.Contains(pullRequest.ReviewerIDs, oldReviewerID) {
 		return model.PullRequest{}, "", ErrNotAssigned
 	}
-	allAvailable, err := u.userRepo.GetReviewersByAuthorID(ctx, pullRequest.AuthorID)
+	pool, err := u.getCandidatePool(ctx, pullRequest.AuthorID, turns)
 	if err != nil {
-		fmt.Println(err)
-		if errors.Is(err, userPkg.ErrTeamOrAuthorNotFound) {
-			return model.PullRequest{}, "", ErrTeamOrAuthorNotFound
-		}
 		return model.PullRequest{}, "", err
 	}
-	if len(allAvailable) == 0 {
+	reviewers, err := u.pickReviewers(ctx, pool, pullRequest.Labels, pullRequest.ReviewerIDs, 1)
+	if err != nil {
+		return model.PullRequest{}, "", err
+	}
+	if len(reviewers) == 0 {
+		u.metrics.NoCandidate()
 		return model.PullRequest{}, "", ErrDontHaveReviewers
 	}
+	pullRequest, err = u.pullRequestRepo.ChangeReviewer(ctx, pullRequestID, oldReviewerID, reviewers[0], reason, turns.Moves())
+	if err != nil {
+		return model.PullRequest{}, "", mapStatusError(err)
+	}
+	return pullRequest, reviewers[0].ID, nil
+}
 
-	validate := make([]model.User, 0, len(allAvailable))
-	for _, reviewer := range allAvailable {
-		if reviewer.ID != oldReviewerID {
-			validate = append(validate, reviewer)
+// DeactivateReviewers deactivates users and hands their OPEN reviews to other candidates in one transaction.
+// teamName, when not empty, is the team the users must still be in. fallbackTeams, when not nil, replace the stored ones.
+func (u *useCase) DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, fallbackTeams []string) ([]model.Reassignment, error) {
+	var reassignments []model.Reassignment
+	err := u.withTurns(func(turns *rotationPkg.Turns) error {
+		var err error
+		reassignments, err = u.deactivateReviewers(ctx, teamName, userIDs, fallbackTeams, turns)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.countReassignments(reassignments, model.ReasonReviewerDeactivated)
+	return reassignments, nil
+}
+
+func (u *useCase) deactivateReviewers(ctx context.Context, teamName string, userIDs []string, fallbackTeams []string, turns *rotationPkg.Turns) ([]model.Reassignment, error) {
+	prs, err := u.pullRequestRepo.GetOpenByReviewers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	leaving := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		leaving[id] = true
+	}
+	pools := make(map[string]*candidatePool)
+	reassignments := make([]model.Reassignment, 0)
+	for _, pr := range prs {
+		pool, ok := pools[pr.AuthorID]
+		if !ok {
+			pool, err = u.getCandidatePool(ctx, pr.AuthorID, turns)
+			if errors.Is(err, ErrTeamOrAuthorNotFound) {
+				pool, err = emptyCandidatePool(pr.AuthorID, turns), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if fallbackTeams != nil {
+				pool.fallbackTeams = fallbackTeams
+			}
+			pools[pr.AuthorID] = pool
+		}
+
+		excluded := append(slices.Clone(pr.ReviewerIDs), userIDs...)
+		for _, reviewerID := range pr.ReviewerIDs {
+			if !leaving[reviewerID] {
+				continue
+			}
+			reassignment := model.Reassignment{
+				PullRequestID: pr.PullRequestID,
+				OldReviewerID: reviewerID,
+			}
+			picked, err := u.pickReviewers(ctx, pool, pr.Labels, excluded, 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(picked) > 0 {
+				reassignment.NewReviewerID = picked[0].ID
+				reassignment.NewReviewerTeam = picked[0].TeamName
+				reassignment.IsFallback = picked[0].IsFallback
+				reassignment.MatchedTags = picked[0].MatchedTags
+				reassignment.DueAt = picked[0].DueAt
+				excluded = append(excluded, picked[0].ID)
+			}
+			reassignments = append(reassignments, reassignment)
 		}
 	}
-	if len(validate) == 0 {
-		return model.PullRequest{}, "", ErrDontHaveReviewers
+
+	err = u.pullRequestRepo.DeactivateReviewers(ctx, teamName, userIDs, prs, reassignments, turns.Moves())
+	if err != nil {
+		return nil, err
 	}
-	reviewers := pickReviewer(validate)
-	pullRequest, err = u.pullRequestRepo.ChangeReviewer(ctx, pullRequestID, oldReviewerID, reviewers[0].ID)
-	return pullRequest, reviewers[0].ID, err
+	return reassignments, nil
 }
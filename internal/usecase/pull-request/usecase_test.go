@@ -0,0 +1,439 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pullRequestPkg "github.com/doverlof/avito_help/internal/client/repo/pull-request"
+	rotationPkg "github.com/doverlof/avito_help/internal/client/repo/rotation"
+	teamPkg "github.com/doverlof/avito_help/internal/client/repo/team"
+	userPkg "github.com/doverlof/avito_help/internal/client/repo/user"
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/selector"
+	"github.com/doverlof/avito_help/internal/workhours"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRotation mimics team_rotation, advance does what rotation.Advance does in the write transaction.
+type memoryRotation struct {
+	cursors map[string]string
+}
+
+func (r *memoryRotation) GetCursor(_ context.Context, teamName string) (string, error) {
+	return r.cursors[teamName], nil
+}
+
+func (r *memoryRotation) advance(moves []model.RotationMove) error {
+	for _, move := range moves {
+		if r.cursors[move.TeamName] != move.From {
+			return fmt.Errorf("%w: %s", rotationPkg.ErrCursorMoved, move.TeamName)
+		}
+	}
+	for _, move := range moves {
+		r.cursors[move.TeamName] = move.To
+	}
+	return nil
+}
+
+// memoryPullRequests keeps created pull requests, the repo methods the tests don't need panic.
+type memoryPullRequests struct {
+	pullRequestPkg.Repo
+	rotation *memoryRotation
+	users    *memoryUsers
+	prs      map[string]model.PullRequest
+	// beforeWrite runs at the start of every write, e.g. to act as a concurrent assignment.
+	beforeWrite func()
+}
+
+func (r *memoryPullRequests) Create(_ context.Context, pullRequest model.CreatePullRequest, reviewers []model.Reviewer, moves []model.RotationMove) (model.PullRequest, error) {
+	if r.beforeWrite != nil {
+		r.beforeWrite()
+	}
+	if _, ok := r.prs[pullRequest.PullRequestID]; ok {
+		return model.PullRequest{}, pullRequestPkg.ErrPRExists
+	}
+	if err := r.rotation.advance(moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	created := model.PullRequest{
+		PullRequestID: pullRequest.PullRequestID,
+		AuthorID:      pullRequest.AuthorID,
+		Status:        model.StatusOpen,
+		Reviewers:     reviewers,
+		ReviewerIDs:   reviewerIDs(reviewers),
+	}
+	r.prs[created.PullRequestID] = created
+	return created, nil
+}
+
+func (r *memoryPullRequests) GetOpenByReviewers(_ context.Context, userIDs []string) ([]model.PullRequest, error) {
+	res := make([]model.PullRequest, 0)
+	for _, pr := range r.prs {
+		if pr.Status == model.StatusOpen && slices.ContainsFunc(pr.ReviewerIDs, func(id string) bool {
+			return slices.Contains(userIDs, id)
+		}) {
+			res = append(res, pr)
+		}
+	}
+	slices.SortFunc(res, func(a, b model.PullRequest) int { return strings.Compare(a.PullRequestID, b.PullRequestID) })
+	return res, nil
+}
+
+// DeactivateReviewers checks planned against the current pull requests like the repo does under the locks.
+func (r *memoryPullRequests) DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, planned []model.PullRequest, reassignments []model.Reassignment, moves []model.RotationMove) error {
+	if r.beforeWrite != nil {
+		r.beforeWrite()
+	}
+	current, _ := r.GetOpenByReviewers(ctx, userIDs)
+	if len(current) != len(planned) {
+		return pullRequestPkg.ErrPlanOutdated
+	}
+	for i := range current {
+		if current[i].PullRequestID != planned[i].PullRequestID || !slices.Equal(current[i].ReviewerIDs, planned[i].ReviewerIDs) {
+			return pullRequestPkg.ErrPlanOutdated
+		}
+	}
+	if err := r.rotation.advance(moves); err != nil {
+		return err
+	}
+	for i := range r.users.users {
+		if slices.Contains(userIDs, r.users.users[i].ID) {
+			r.users.users[i].IsActive = false
+		}
+	}
+	for _, reassignment := range reassignments {
+		pr := r.prs[reassignment.PullRequestID]
+		if reassignment.NewReviewerID == "" {
+			pr.NeedsReviewer = true
+		} else {
+			pr.ReviewerIDs = slices.Clone(pr.ReviewerIDs)
+			pr.ReviewerIDs[slices.Index(pr.ReviewerIDs, reassignment.OldReviewerID)] = reassignment.NewReviewerID
+		}
+		r.prs[pr.PullRequestID] = pr
+	}
+	return nil
+}
+
+func (r *memoryPullRequests) GetByID(_ context.Context, pullRequestID string) (model.PullRequest, error) {
+	pr, ok := r.prs[pullRequestID]
+	if !ok {
+		return model.PullRequest{}, pullRequestPkg.ErrPRNotFound
+	}
+	return pr, nil
+}
+
+// Reopen replaces the reviewers of the pull request with kept and the new reviewers of reassignments.
+func (r *memoryPullRequests) Reopen(_ context.Context, pullRequestID string, kept []model.Reviewer, reassignments []model.Reassignment, moves []model.RotationMove) (model.PullRequest, error) {
+	if err := r.rotation.advance(moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	pr := r.prs[pullRequestID]
+	pr.Status = model.StatusOpen
+	pr.Reviewers = slices.Clone(kept)
+	for _, reassignment := range reassignments {
+		if reassignment.NewReviewerID == "" {
+			pr.NeedsReviewer = true
+			continue
+		}
+		pr.Reviewers = append(pr.Reviewers, model.Reviewer{ID: reassignment.NewReviewerID, DueAt: reassignment.DueAt})
+	}
+	pr.ReviewerIDs = reviewerIDs(pr.Reviewers)
+	r.prs[pullRequestID] = pr
+	return pr, nil
+}
+
+// ChangeReviewer checks the status like the repo does under the pull request lock.
+func (r *memoryPullRequests) ChangeReviewer(_ context.Context, pullRequestID, oldReviewerID string, reviewer model.Reviewer, _ string, moves []model.RotationMove) (model.PullRequest, error) {
+	if r.beforeWrite != nil {
+		r.beforeWrite()
+	}
+	pr := r.prs[pullRequestID]
+	switch pr.Status {
+	case model.StatusMerge:
+		return model.PullRequest{}, pullRequestPkg.ErrPRMerged
+	case model.StatusClosed:
+		return model.PullRequest{}, pullRequestPkg.ErrPRClosed
+	}
+	if err := r.rotation.advance(moves); err != nil {
+		return model.PullRequest{}, err
+	}
+	pr.ReviewerIDs = slices.Clone(pr.ReviewerIDs)
+	pr.ReviewerIDs[slices.Index(pr.ReviewerIDs, oldReviewerID)] = reviewer.ID
+	r.prs[pullRequestID] = pr
+	return pr, nil
+}
+
+func (r *memoryPullRequests) CountOpenReviews(_ context.Context, userIDs []string) (map[string]int, error) {
+	return make(map[string]int, len(userIDs)), nil
+}
+
+type memoryUsers struct {
+	userPkg.Repo
+	users     []model.User
+	schedules map[string]model.WorkSchedule
+}
+
+func (r *memoryUsers) GetByID(_ context.Context, userID string) (model.User, error) {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return model.User{}, userPkg.ErrUserNotFound
+}
+
+func (r *memoryUsers) GetReviewersByAuthorID(ctx context.Context, authorID string, _ time.Duration) ([]model.User, error) {
+	author, err := r.GetByID(ctx, authorID)
+	if err != nil {
+		return nil, userPkg.ErrTeamOrAuthorNotFound
+	}
+	res := make([]model.User, 0)
+	for _, user := range r.users {
+		if user.TeamName == author.TeamName && user.ID != authorID && user.IsActive {
+			res = append(res, user)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryUsers) GetSchedules(_ context.Context, userIDs []string) (map[string]model.WorkSchedule, error) {
+	res := make(map[string]model.WorkSchedule)
+	for _, id := range userIDs {
+		if schedule, ok := r.schedules[id]; ok {
+			res[id] = schedule
+		}
+	}
+	return res, nil
+}
+
+type memoryTeams struct {
+	teamPkg.Repo
+	settings model.TeamSettings
+}
+
+func (r *memoryTeams) GetSettings(_ context.Context, name string) (model.TeamSettings, error) {
+	settings := r.settings
+	settings.TeamName = name
+	return settings, nil
+}
+
+func (r *memoryTeams) GetFallbacks(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+type noMetrics struct{}
+
+func (noMetrics) Created()          {}
+func (noMetrics) Merged()           {}
+func (noMetrics) Reassigned(string) {}
+func (noMetrics) NoCandidate()      {}
+
+type fixture struct {
+	useCase  UseCase
+	prs      *memoryPullRequests
+	users    *memoryUsers
+	teams    *memoryTeams
+	rotation *memoryRotation
+}
+
+// newFixture builds the use case over team backend: author u1 and reviewers u2..u4, picked round-robin.
+func newFixture(t *testing.T) *fixture {
+	rotation := &memoryRotation{cursors: map[string]string{}}
+	users := &memoryUsers{schedules: map[string]model.WorkSchedule{}}
+	f := &fixture{
+		prs:      &memoryPullRequests{rotation: rotation, users: users, prs: map[string]model.PullRequest{}},
+		users:    users,
+		teams:    &memoryTeams{settings: model.DefaultTeamSettings("")},
+		rotation: rotation,
+	}
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		f.users.users = append(f.users.users, model.User{ID: id, TeamName: "backend", IsActive: true})
+	}
+	f.teams.settings.Strategy = string(selector.StrategyRoundRobin)
+	selectors, err := selector.NewRegistry(config.SelectorConfig{Strategy: string(selector.StrategyRandom)}, f.prs, f.users)
+	require.NoError(t, err)
+	f.useCase = New(f.prs, f.users, f.teams, rotation, selectors, noMetrics{})
+	return f
+}
+
+func TestCreateAdvancesRotation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	created, err := f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-1", AuthorID: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3"}, created.ReviewerIDs)
+	assert.Equal(t, "u3", f.rotation.cursors["backend"])
+}
+
+func TestFailedCreateKeepsRotation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.prs.prs["pr-1"] = model.PullRequest{PullRequestID: "pr-1"}
+
+	// a redelivery of an existing pull request
+	_, err := f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-1", AuthorID: "u1"})
+	require.ErrorIs(t, err, ErrPRExists)
+	assert.Empty(t, f.rotation.cursors["backend"])
+
+	f.teams.settings.MinReviewers = 4
+	_, err = f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-2", AuthorID: "u1"})
+	require.ErrorIs(t, err, ErrNotEnoughReviewers)
+	assert.Empty(t, f.rotation.cursors["backend"])
+
+	// the turns are still there for the next pull request
+	f.teams.settings.MinReviewers = 1
+	created, err := f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-2", AuthorID: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3"}, created.ReviewerIDs)
+}
+
+func TestCreatePlansAgainAfterConcurrentAssignment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.prs.beforeWrite = func() {
+		// another instance gives u2 and u3 their turns between the plan and the write
+		f.rotation.cursors["backend"] = "u3"
+		f.prs.beforeWrite = nil
+	}
+
+	created, err := f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-1", AuthorID: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u2"}, created.ReviewerIDs)
+	assert.Equal(t, "u2", f.rotation.cursors["backend"])
+}
+
+func openPR(id string, reviewerIDs ...string) model.PullRequest {
+	return model.PullRequest{PullRequestID: id, AuthorID: "u1", Status: model.StatusOpen, ReviewerIDs: reviewerIDs}
+}
+
+func TestDeactivateReviewersPlansAgainAfterConcurrentAssignment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.prs.prs["pr-1"] = openPR("pr-1", "u2", "u3")
+	f.prs.beforeWrite = func() {
+		// a pull request created between the plan and the write gets u2 as well
+		f.prs.prs["pr-2"] = openPR("pr-2", "u2")
+		f.prs.beforeWrite = nil
+	}
+
+	reassignments, err := f.useCase.DeactivateReviewers(ctx, "", []string{"u2"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Reassignment{
+		{PullRequestID: "pr-1", OldReviewerID: "u2", NewReviewerID: "u4", NewReviewerTeam: "backend"},
+		{PullRequestID: "pr-2", OldReviewerID: "u2", NewReviewerID: "u3", NewReviewerTeam: "backend"},
+	}, reassignments)
+	assert.Equal(t, []string{"u4", "u3"}, f.prs.prs["pr-1"].ReviewerIDs)
+	assert.Equal(t, []string{"u3"}, f.prs.prs["pr-2"].ReviewerIDs)
+
+	// deactivating again finds nothing left to move
+	cursor := f.rotation.cursors["backend"]
+	reassignments, err = f.useCase.DeactivateReviewers(ctx, "", []string{"u2"}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, reassignments)
+	assert.Equal(t, cursor, f.rotation.cursors["backend"])
+	assert.Equal(t, []string{"u4", "u3"}, f.prs.prs["pr-1"].ReviewerIDs)
+}
+
+func TestDeactivateReviewersGivesUpOnConstantChanges(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.prs.prs["pr-1"] = openPR("pr-1", "u2")
+	created := 0
+	f.prs.beforeWrite = func() {
+		created++
+		id := fmt.Sprintf("pr-%d", created+1)
+		f.prs.prs[id] = openPR(id, "u2")
+	}
+
+	_, err := f.useCase.DeactivateReviewers(ctx, "", []string{"u2"}, nil)
+	require.ErrorIs(t, err, ErrConcurrentChange)
+	require.ErrorIs(t, err, pullRequestPkg.ErrPlanOutdated)
+	assert.Equal(t, maxPlanAttempts, created)
+	assert.Equal(t, []string{"u2"}, f.prs.prs["pr-1"].ReviewerIDs)
+	assert.Empty(t, f.rotation.cursors["backend"])
+}
+
+func TestReopenRestartsSLA(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.teams.settings.ReviewSLAHours = 4
+	closedAt := time.Now().Add(-48 * time.Hour)
+	f.prs.prs["pr-1"] = model.PullRequest{
+		PullRequestID: "pr-1",
+		AuthorID:      "u1",
+		Status:        model.StatusClosed,
+		Reviewers: []model.Reviewer{
+			{ID: "u2", IsActive: true, DueAt: closedAt},
+			{ID: "u3", IsActive: false, DueAt: closedAt},
+		},
+		ReviewerIDs: []string{"u2", "u3"},
+	}
+
+	before := time.Now()
+	reopened, reassignments, err := f.useCase.Reopen(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Len(t, reassignments, 1)
+	assert.Equal(t, "u3", reassignments[0].OldReviewerID)
+	assert.Equal(t, []string{"u2", "u4"}, reopened.ReviewerIDs)
+	// the kept reviewer gets a whole SLA from the reopening, like the new one
+	for _, reviewer := range reopened.Reviewers {
+		assert.WithinDuration(t, before.Add(4*time.Hour), reviewer.DueAt, time.Minute, reviewer.ID)
+	}
+}
+
+func TestCreateSetsDueAt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// u3 works one hour a day, a 4 hour SLA takes them four days
+	f.users.schedules["u3"] = model.WorkSchedule{
+		Timezone:  "UTC",
+		EndMinute: 60,
+		Weekdays:  []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	}
+
+	created, err := f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-1", AuthorID: "u1"})
+	require.NoError(t, err)
+	for _, reviewer := range created.Reviewers {
+		assert.True(t, reviewer.DueAt.IsZero(), "the team has no SLA")
+	}
+
+	f.teams.settings.ReviewSLAHours = 4
+	before := time.Now()
+	created, err = f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-2", AuthorID: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u4", "u2"}, created.ReviewerIDs)
+	// without a schedule the SLA runs around the clock
+	assert.WithinDuration(t, before.Add(4*time.Hour), created.Reviewers[0].DueAt, time.Minute)
+
+	created, err = f.useCase.Create(ctx, model.CreatePullRequest{PullRequestID: "pr-3", AuthorID: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u3", "u4"}, created.ReviewerIDs)
+	// the schedule skips the hours u3 doesn't work
+	dueAt := created.Reviewers[0].DueAt
+	assert.True(t, dueAt.After(before.Add(72*time.Hour)), dueAt)
+	assert.WithinDuration(t, workhours.Add(f.users.schedules["u3"], before, 4*time.Hour), dueAt, time.Minute)
+	assert.WithinDuration(t, before.Add(4*time.Hour), created.Reviewers[1].DueAt, time.Minute)
+}
+
+func TestReassignAfterConcurrentMerge(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.prs.prs["pr-1"] = openPR("pr-1", "u2", "u3")
+	f.prs.beforeWrite = func() {
+		pr := f.prs.prs["pr-1"]
+		pr.Status = model.StatusMerge
+		f.prs.prs["pr-1"] = pr
+	}
+
+	_, _, err := f.useCase.Reassign(ctx, "pr-1", "u2")
+	require.ErrorIs(t, err, ErrPRAlreadyMerged)
+	assert.Equal(t, []string{"u2", "u3"}, f.prs.prs["pr-1"].ReviewerIDs)
+	assert.Empty(t, f.rotation.cursors["backend"])
+}
@@ -2,13 +2,24 @@ package stats
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/doverlof/avito_help/internal/client/repo/pull-request"
 	"github.com/doverlof/avito_help/internal/model"
 )
 
+// DefaultWindow is the period statistics cover when the request doesn't set one.
+const DefaultWindow = 30 * 24 * time.Hour
+
+var ErrInvalidWindow = errors.New("from must be before to")
+
 type UseCase interface {
-	GetUserStatistics(ctx context.Context) ([]model.UserStatistics, error)
+	GetUserStatistics(ctx context.Context, filter model.StatsFilter) ([]model.UserStatistics, error)
+	GetTeamStatistics(ctx context.Context, filter model.StatsFilter) ([]model.TeamStatistics, error)
+	GetSLACompliance(ctx context.Context, from, to time.Time, teamName string) (model.SLACompliance, error)
+	GetLatency(ctx context.Context, from, to time.Time, teamName string) (model.LatencyReport, error)
+	RecomputeUserStats(ctx context.Context) (int, error)
 }
 
 type useCase struct {
@@ -21,6 +32,73 @@ func New(prRepo pull_request.Repo) UseCase {
 	}
 }
 
-func (u *useCase) GetUserStatistics(ctx context.Context) ([]model.UserStatistics, error) {
-	return u.prRepo.GetUserStatistics(ctx)
+// GetUserStatistics returns the counters of every user, all-time unless the filter sets a period.
+func (u *useCase) GetUserStatistics(ctx context.Context, filter model.StatsFilter) ([]model.UserStatistics, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	return u.prRepo.GetUserStatistics(ctx, filter)
+}
+
+// GetTeamStatistics aggregates pull requests and review load per team, all-time unless the filter sets a period.
+func (u *useCase) GetTeamStatistics(ctx context.Context, filter model.StatsFilter) ([]model.TeamStatistics, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	return u.prRepo.GetTeamStatistics(ctx, filter)
+}
+
+func validateFilter(filter model.StatsFilter) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+// GetSLACompliance reports how many reviews of pull requests merged within [from, to) started within the SLA.
+// A zero to means now, a zero from means DefaultWindow before to.
+func (u *useCase) GetSLACompliance(ctx context.Context, from, to time.Time, teamName string) (model.SLACompliance, error) {
+	from, to, err := window(from, to)
+	if err != nil {
+		return model.SLACompliance{}, err
+	}
+	teams, err := u.prRepo.GetSLACompliance(ctx, from, to, teamName)
+	if err != nil {
+		return model.SLACompliance{}, err
+	}
+	res := model.SLACompliance{From: from, To: to, Teams: teams}
+	for _, team := range teams {
+		res.Total += team.Total
+		res.WithinSLA += team.WithinSLA
+	}
+	return res, nil
+}
+
+// GetLatency reports time-to-merge and assignment-to-merge percentiles of pull requests merged within [from, to),
+// with the same defaults as GetSLACompliance. The team filter matches the author's team.
+func (u *useCase) GetLatency(ctx context.Context, from, to time.Time, teamName string) (model.LatencyReport, error) {
+	from, to, err := window(from, to)
+	if err != nil {
+		return model.LatencyReport{}, err
+	}
+	return u.prRepo.GetLatency(ctx, from, to, teamName)
+}
+
+// RecomputeUserStats rebuilds the all-time user counters from scratch, for repair after a manual data fix.
+func (u *useCase) RecomputeUserStats(ctx context.Context) (int, error) {
+	return u.prRepo.RecomputeUserStats(ctx)
+}
+
+// window applies the defaults of a period: a zero to means now, a zero from means DefaultWindow before to.
+func window(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultWindow)
+	}
+	if !from.Before(to) {
+		return from, to, ErrInvalidWindow
+	}
+	return from, to, nil
 }
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pullRequestPkg "github.com/doverlof/avito_help/internal/client/repo/pull-request"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slaRepo returns teams and records the period it was asked for, the other repo methods panic.
+type slaRepo struct {
+	pullRequestPkg.Repo
+	teams    []model.TeamSLACompliance
+	from, to time.Time
+}
+
+func (r *slaRepo) GetSLACompliance(_ context.Context, from, to time.Time, _ string) ([]model.TeamSLACompliance, error) {
+	r.from, r.to = from, to
+	return r.teams, nil
+}
+
+func TestGetSLACompliance(t *testing.T) {
+	ctx := context.Background()
+	repo := &slaRepo{teams: []model.TeamSLACompliance{
+		{TeamName: "backend", Total: 4, WithinSLA: 3},
+		{TeamName: "frontend", Total: 2, WithinSLA: 0},
+	}}
+
+	before := time.Now()
+	compliance, err := New(repo).GetSLACompliance(ctx, time.Time{}, time.Time{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 6, compliance.Total)
+	assert.Equal(t, 3, compliance.WithinSLA)
+	assert.Equal(t, repo.teams, compliance.Teams)
+	assert.WithinDuration(t, before, compliance.To, time.Second)
+	assert.Equal(t, DefaultWindow, compliance.To.Sub(compliance.From))
+	assert.Equal(t, compliance.From, repo.from)
+
+	_, err = New(repo).GetSLACompliance(ctx, before, before.Add(-time.Hour), "")
+	require.ErrorIs(t, err, ErrInvalidWindow)
+}
+
+func TestGetSLAComplianceWithoutReviews(t *testing.T) {
+	compliance, err := New(&slaRepo{teams: []model.TeamSLACompliance{}}).
+		GetSLACompliance(context.Background(), time.Time{}, time.Time{}, "backend")
+	require.NoError(t, err)
+	assert.Zero(t, compliance.Total)
+	assert.Nil(t, model.CompliancePercent(compliance.WithinSLA, compliance.Total))
+}
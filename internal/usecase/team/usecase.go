@@ -3,37 +3,88 @@ package team
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
+	rotationRepo "github.com/doverlof/avito_help/internal/client/repo/rotation"
 	teamRepo "github.com/doverlof/avito_help/internal/client/repo/team"
+	userRepo "github.com/doverlof/avito_help/internal/client/repo/user"
+	"github.com/doverlof/avito_help/internal/codeowners"
 	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/selector"
 )
 
 var (
 	ErrTeamNotFound = errors.New("team not found or don't have members")
 	ErrTeamExists   = errors.New("team already exists")
+
+	ErrInvalidSettings    = errors.New("invalid team settings")
+	ErrMemberNotFound     = errors.New("user is not a member of the team")
+	ErrNoMembersSelected  = errors.New("either user_ids or all must be set")
+	ErrInvalidFallbacks   = errors.New("invalid fallback teams")
+	ErrDuplicateMember    = errors.New("member is listed twice")
+	ErrMemberDeactivation = errors.New("active members are deactivated by /team/deactivateMembers")
+
+	ErrInvalidUpdate      = errors.New("invalid team update")
+	ErrInvalidDelete      = errors.New("invalid team delete")
+	ErrTeamHasOpenReviews = errors.New("team members still have open reviews")
+
+	ErrInvalidCodeOwners  = errors.New("invalid CODEOWNERS file")
+	ErrCodeOwnersNotFound = errors.New("CODEOWNERS file not found")
 )
 
 type UseCase interface {
-	Add(ctx context.Context, team model.Team) error
+	Add(ctx context.Context, team model.Team) (model.TeamAddResult, error)
 	Get(ctx context.Context, name string) (model.Team, error)
+	GetSettings(ctx context.Context, name string) (model.TeamSettings, error)
+	SetSettings(ctx context.Context, settings model.TeamSettings) (model.TeamSettings, error)
+	DeactivateMembers(ctx context.Context, req model.DeactivateMembers) (model.DeactivationReport, error)
+	GetFallbacks(ctx context.Context, name string) ([]string, error)
+	SetFallbacks(ctx context.Context, name string, fallbackTeams []string) ([]string, error)
+	GetCodeOwners(ctx context.Context, name, repository string) (model.CodeOwners, error)
+	SetCodeOwners(ctx context.Context, codeOwners model.CodeOwners) (model.CodeOwners, int, error)
+	Update(ctx context.Context, update model.TeamUpdate) (model.TeamUpdateResult, error)
+	Rename(ctx context.Context, name, newName string) error
+	Delete(ctx context.Context, req model.TeamDelete) ([]string, error)
+}
+
+// ReviewReassigner deactivates users and moves their open reviews in one transaction.
+type ReviewReassigner interface {
+	DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, fallbackTeams []string) ([]model.Reassignment, error)
 }
 
 type useCase struct {
-	repo teamRepo.Repo
+	repo         teamRepo.Repo
+	rotationRepo rotationRepo.Repo
+	userRepo     userRepo.Repo
+	reassigner   ReviewReassigner
 }
 
-func New(repo teamRepo.Repo) UseCase {
+func New(repo teamRepo.Repo, rotationRepo rotationRepo.Repo, userRepo userRepo.Repo, reassigner ReviewReassigner) UseCase {
 	return &useCase{
-		repo: repo,
+		repo:         repo,
+		rotationRepo: rotationRepo,
+		userRepo:     userRepo,
+		reassigner:   reassigner,
 	}
 }
 
-func (u *useCase) Add(ctx context.Context, model model.Team) error {
-	err := u.repo.Add(ctx, model)
-	if errors.Is(err, teamRepo.ErrTeamExists) {
-		return ErrTeamExists
+func (u *useCase) Add(ctx context.Context, team model.Team) (model.TeamAddResult, error) {
+	seen := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		if seen[member.ID] {
+			return model.TeamAddResult{}, fmt.Errorf("%w: %s", ErrDuplicateMember, member.ID)
+		}
+		seen[member.ID] = true
 	}
-	return err
+	result, err := u.repo.Add(ctx, team)
+	switch {
+	case errors.Is(err, teamRepo.ErrTeamExists):
+		return model.TeamAddResult{}, ErrTeamExists
+	case errors.Is(err, teamRepo.ErrMemberDeactivation):
+		return model.TeamAddResult{}, fmt.Errorf("%w: %v", ErrMemberDeactivation, err)
+	}
+	return result, err
 }
 
 func (u *useCase) Get(ctx context.Context, name string) (model.Team, error) {
@@ -41,5 +92,264 @@ func (u *useCase) Get(ctx context.Context, name string) (model.Team, error) {
 	if errors.Is(err, teamRepo.ErrTeamNotFound) {
 		return model.Team{}, ErrTeamNotFound
 	}
-	return team, err
+	if err != nil {
+		return model.Team{}, err
+	}
+
+	cursor, err := u.rotationRepo.GetCursor(ctx, name)
+	if err != nil {
+		return model.Team{}, err
+	}
+	// The rotation goes over the same members reviewers are picked from: active and not absent
+	// within the team's absence lookahead.
+	settings, err := u.repo.GetSettings(ctx, name)
+	if err != nil {
+		return model.Team{}, err
+	}
+	available, err := u.userRepo.GetActiveByTeam(ctx, name, settings.AbsenceLookahead())
+	if err != nil {
+		return model.Team{}, err
+	}
+	active := make([]string, 0, len(available))
+	for _, member := range available {
+		active = append(active, member.ID)
+	}
+	team.Rotation = rotationRepo.Order(active, cursor)
+	return team, nil
+}
+
+func (u *useCase) GetSettings(ctx context.Context, name string) (model.TeamSettings, error) {
+	settings, err := u.repo.GetSettings(ctx, name)
+	if errors.Is(err, teamRepo.ErrTeamNotFound) {
+		return model.TeamSettings{}, ErrTeamNotFound
+	}
+	return settings, err
+}
+
+func (u *useCase) SetSettings(ctx context.Context, settings model.TeamSettings) (model.TeamSettings, error) {
+	if err := validateSettings(settings); err != nil {
+		return model.TeamSettings{}, err
+	}
+	stored, err := u.repo.SetSettings(ctx, settings)
+	if errors.Is(err, teamRepo.ErrTeamNotFound) {
+		return model.TeamSettings{}, ErrTeamNotFound
+	}
+	return stored, err
+}
+
+func validateSettings(settings model.TeamSettings) error {
+	switch {
+	case settings.MinReviewers < 0:
+		return fmt.Errorf("%w: min_reviewers must be non-negative", ErrInvalidSettings)
+	case settings.MaxReviewers < settings.MinReviewers:
+		return fmt.Errorf("%w: max_reviewers must be greater or equal to min_reviewers", ErrInvalidSettings)
+	case settings.RequiredApprovals < 0:
+		return fmt.Errorf("%w: required_approvals must be non-negative", ErrInvalidSettings)
+	case settings.RequiredApprovals > settings.MaxReviewers:
+		return fmt.Errorf("%w: required_approvals can't exceed max_reviewers", ErrInvalidSettings)
+	case settings.ReviewSLAHours < 0:
+		return fmt.Errorf("%w: review_sla_hours must be non-negative", ErrInvalidSettings)
+	case settings.AbsenceLookaheadHours < 0:
+		return fmt.Errorf("%w: absence_lookahead_hours must be non-negative", ErrInvalidSettings)
+	case settings.Strategy != "" && !selector.IsKnown(settings.Strategy):
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidSettings, settings.Strategy)
+	}
+	return nil
+}
+
+// DeactivateMembers deactivates the selected members all-or-nothing.
+func (u *useCase) DeactivateMembers(ctx context.Context, req model.DeactivateMembers) (model.DeactivationReport, error) {
+	if !req.All && len(req.UserIDs) == 0 {
+		return model.DeactivationReport{}, ErrNoMembersSelected
+	}
+	team, err := u.Get(ctx, req.TeamName)
+	if err != nil {
+		return model.DeactivationReport{}, err
+	}
+	for _, fallback := range req.FallbackTeams {
+		if _, err = u.repo.Get(ctx, fallback); err != nil {
+			if errors.Is(err, teamRepo.ErrTeamNotFound) {
+				return model.DeactivationReport{}, fmt.Errorf("%w: fallback team %s", ErrTeamNotFound, fallback)
+			}
+			return model.DeactivationReport{}, err
+		}
+	}
+
+	members := make(map[string]model.Member, len(team.Members))
+	for _, member := range team.Members {
+		members[member.ID] = member
+	}
+	userIDs := req.UserIDs
+	if req.All {
+		userIDs = make([]string, 0, len(team.Members))
+		for _, member := range team.Members {
+			userIDs = append(userIDs, member.ID)
+		}
+	}
+
+	report := model.DeactivationReport{
+		TeamName:        req.TeamName,
+		Deactivated:     make([]string, 0, len(userIDs)),
+		AlreadyInactive: make([]string, 0),
+	}
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		member, ok := members[id]
+		if !ok {
+			return model.DeactivationReport{}, fmt.Errorf("%w: %s", ErrMemberNotFound, id)
+		}
+		if member.IsActive {
+			report.Deactivated = append(report.Deactivated, id)
+		} else {
+			report.AlreadyInactive = append(report.AlreadyInactive, id)
+		}
+	}
+
+	selected := append(slices.Clone(report.Deactivated), report.AlreadyInactive...)
+	report.Reassignments, err = u.reassigner.DeactivateReviewers(ctx, req.TeamName, selected, req.FallbackTeams)
+	if err != nil {
+		return model.DeactivationReport{}, err
+	}
+	return report, nil
+}
+
+func (u *useCase) GetFallbacks(ctx context.Context, name string) ([]string, error) {
+	if _, err := u.GetSettings(ctx, name); err != nil {
+		return nil, err
+	}
+	return u.repo.GetFallbacks(ctx, name)
+}
+
+func (u *useCase) SetFallbacks(ctx context.Context, name string, fallbackTeams []string) ([]string, error) {
+	seen := make(map[string]bool, len(fallbackTeams))
+	for _, fallback := range fallbackTeams {
+		if fallback == name {
+			return nil, fmt.Errorf("%w: team can't be its own fallback", ErrInvalidFallbacks)
+		}
+		if seen[fallback] {
+			return nil, fmt.Errorf("%w: duplicate team %s", ErrInvalidFallbacks, fallback)
+		}
+		seen[fallback] = true
+	}
+	if _, err := u.GetSettings(ctx, name); err != nil {
+		return nil, err
+	}
+
+	err := u.repo.SetFallbacks(ctx, name, fallbackTeams)
+	if errors.Is(err, teamRepo.ErrTeamNotFound) {
+		return nil, fmt.Errorf("%w: unknown fallback team", ErrTeamNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetFallbacks(ctx, name)
+}
+
+func (u *useCase) Update(ctx context.Context, update model.TeamUpdate) (model.TeamUpdateResult, error) {
+	if len(update.Add) == 0 && len(update.Remove) == 0 {
+		return model.TeamUpdateResult{}, fmt.Errorf("%w: add_members or remove_members must be set", ErrInvalidUpdate)
+	}
+	seen := make(map[string]bool, len(update.Add)+len(update.Remove))
+	for _, member := range update.Add {
+		if seen[member.ID] {
+			return model.TeamUpdateResult{}, fmt.Errorf("%w: %s", ErrDuplicateMember, member.ID)
+		}
+		seen[member.ID] = true
+	}
+	for _, id := range update.Remove {
+		if seen[id] {
+			return model.TeamUpdateResult{}, fmt.Errorf("%w: %s", ErrDuplicateMember, id)
+		}
+		seen[id] = true
+	}
+
+	result, err := u.repo.Update(ctx, update)
+	switch {
+	case errors.Is(err, teamRepo.ErrTeamNotFound):
+		return model.TeamUpdateResult{}, ErrTeamNotFound
+	case errors.Is(err, teamRepo.ErrMemberNotFound):
+		return model.TeamUpdateResult{}, ErrMemberNotFound
+	case errors.Is(err, teamRepo.ErrMemberDeactivation):
+		return model.TeamUpdateResult{}, fmt.Errorf("%w: %v", ErrMemberDeactivation, err)
+	case err != nil:
+		return model.TeamUpdateResult{}, err
+	}
+	result.Team, err = u.Get(ctx, update.Name)
+	if err != nil {
+		return model.TeamUpdateResult{}, err
+	}
+	return result, nil
+}
+
+func (u *useCase) Rename(ctx context.Context, name, newName string) error {
+	if newName == "" || newName == name {
+		return fmt.Errorf("%w: new_team_name must differ from team_name", ErrInvalidUpdate)
+	}
+	err := u.repo.Rename(ctx, name, newName)
+	switch {
+	case errors.Is(err, teamRepo.ErrTeamNotFound):
+		return ErrTeamNotFound
+	case errors.Is(err, teamRepo.ErrTeamExists):
+		return ErrTeamExists
+	}
+	return err
+}
+
+// Delete removes the team according to req.Policy and returns the affected members.
+func (u *useCase) Delete(ctx context.Context, req model.TeamDelete) ([]string, error) {
+	switch req.Policy {
+	case model.DeletePolicyRefuse:
+		if req.MoveTo != "" {
+			return nil, fmt.Errorf("%w: move_to is only allowed with %s", ErrInvalidDelete, model.DeletePolicyMove)
+		}
+	case model.DeletePolicyMove:
+		if req.MoveTo == "" || req.MoveTo == req.Name {
+			return nil, fmt.Errorf("%w: move_to must name another team", ErrInvalidDelete)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown policy %q", ErrInvalidDelete, req.Policy)
+	}
+
+	members, err := u.repo.Delete(ctx, req.Name, req.MoveTo)
+	switch {
+	case errors.Is(err, teamRepo.ErrTeamNotFound):
+		return nil, ErrTeamNotFound
+	case errors.Is(err, teamRepo.ErrTeamHasOpenReviews):
+		return nil, ErrTeamHasOpenReviews
+	}
+	return members, err
+}
+
+func (u *useCase) GetCodeOwners(ctx context.Context, name, repository string) (model.CodeOwners, error) {
+	if _, err := u.GetSettings(ctx, name); err != nil {
+		return model.CodeOwners{}, err
+	}
+	codeOwners, err := u.repo.GetCodeOwners(ctx, name, repository)
+	if errors.Is(err, teamRepo.ErrCodeOwnersNotFound) {
+		return model.CodeOwners{}, ErrCodeOwnersNotFound
+	}
+	return codeOwners, err
+}
+
+// SetCodeOwners stores the CODEOWNERS file after checking that it parses and returns the number of rules in it.
+func (u *useCase) SetCodeOwners(ctx context.Context, codeOwners model.CodeOwners) (model.CodeOwners, int, error) {
+	if codeOwners.Repository == "" {
+		return model.CodeOwners{}, 0, fmt.Errorf("%w: repository is required", ErrInvalidCodeOwners)
+	}
+	rules, err := codeowners.Parse(codeOwners.Content)
+	if err != nil {
+		return model.CodeOwners{}, 0, fmt.Errorf("%w: %v", ErrInvalidCodeOwners, err)
+	}
+	stored, err := u.repo.SetCodeOwners(ctx, codeOwners)
+	if errors.Is(err, teamRepo.ErrTeamNotFound) {
+		return model.CodeOwners{}, 0, ErrTeamNotFound
+	}
+	if err != nil {
+		return model.CodeOwners{}, 0, err
+	}
+	return stored, len(rules.Rules), nil
 }
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	userRepo "github.com/doverlof/avito_help/internal/client/repo/user"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+func (u *useCase) ListAbsences(ctx context.Context, userID string, includePast bool) ([]model.Absence, error) {
+	absences, err := u.repo.ListAbsences(ctx, userID, includePast)
+	if errors.Is(err, userRepo.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return absences, err
+}
+
+// AddAbsence records a period when the user is away. Absences may overlap, the user is away
+// while any of them lasts.
+func (u *useCase) AddAbsence(ctx context.Context, absence model.Absence) (model.Absence, error) {
+	if err := validateAbsence(absence); err != nil {
+		return model.Absence{}, err
+	}
+	stored, err := u.repo.AddAbsence(ctx, absence)
+	if errors.Is(err, userRepo.ErrUserNotFound) {
+		return model.Absence{}, ErrUserNotFound
+	}
+	return stored, err
+}
+
+func (u *useCase) UpdateAbsence(ctx context.Context, absence model.Absence) (model.Absence, error) {
+	if err := validateAbsence(absence); err != nil {
+		return model.Absence{}, err
+	}
+	stored, err := u.repo.UpdateAbsence(ctx, absence)
+	if errors.Is(err, userRepo.ErrAbsenceNotFound) {
+		return model.Absence{}, ErrAbsenceNotFound
+	}
+	return stored, err
+}
+
+func (u *useCase) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	err := u.repo.DeleteAbsence(ctx, absenceID)
+	if errors.Is(err, userRepo.ErrAbsenceNotFound) {
+		return ErrAbsenceNotFound
+	}
+	return err
+}
+
+func validateAbsence(absence model.Absence) error {
+	switch {
+	case absence.StartsAt.IsZero() || absence.EndsAt.IsZero():
+		return fmt.Errorf("%w: starts_at and ends_at are required", ErrInvalidAbsence)
+	case !absence.EndsAt.After(absence.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidAbsence)
+	}
+	return nil
+}
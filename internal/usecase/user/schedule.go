@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	userRepo "github.com/doverlof/avito_help/internal/client/repo/user"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/doverlof/avito_help/internal/workhours"
+)
+
+func (u *useCase) GetSchedule(ctx context.Context, userID string) (model.WorkSchedule, error) {
+	if _, err := u.GetByID(ctx, userID); err != nil {
+		return model.WorkSchedule{}, err
+	}
+	schedules, err := u.repo.GetSchedules(ctx, []string{userID})
+	if err != nil {
+		return model.WorkSchedule{}, err
+	}
+	schedule, ok := schedules[userID]
+	if !ok {
+		return model.WorkSchedule{}, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// SetSchedule replaces the working hours of the user, see workhours.Validate for what is accepted.
+func (u *useCase) SetSchedule(ctx context.Context, userID string, schedule model.WorkSchedule) (model.WorkSchedule, error) {
+	if err := workhours.Validate(schedule); err != nil {
+		return model.WorkSchedule{}, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	err := u.repo.SetSchedule(ctx, userID, schedule)
+	if errors.Is(err, userRepo.ErrUserNotFound) {
+		return model.WorkSchedule{}, ErrUserNotFound
+	}
+	if err != nil {
+		return model.WorkSchedule{}, err
+	}
+	return u.GetSchedule(ctx, userID)
+}
@@ -3,36 +3,73 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	userRepo "github.com/doverlof/avito_help/internal/client/repo/user"
 	"github.com/doverlof/avito_help/internal/model"
 )
 
+const maxTagLength = 64
+
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidSkills   = errors.New("invalid skills")
+	ErrInvalidAbsence  = errors.New("invalid absence")
+	ErrAbsenceNotFound = errors.New("absence not found")
+
+	ErrInvalidSchedule  = errors.New("invalid working hours")
+	ErrScheduleNotFound = errors.New("working hours are not set")
 )
 
 type UseCase interface {
-	SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error)
+	SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, []model.Reassignment, error)
 	GetByID(ctx context.Context, userID string) (model.User, error)
+	GetSkills(ctx context.Context, userID string) ([]model.Skill, error)
+	SetSkills(ctx context.Context, userID string, skills []model.Skill) ([]model.Skill, error)
+	ListAbsences(ctx context.Context, userID string, includePast bool) ([]model.Absence, error)
+	AddAbsence(ctx context.Context, absence model.Absence) (model.Absence, error)
+	UpdateAbsence(ctx context.Context, absence model.Absence) (model.Absence, error)
+	DeleteAbsence(ctx context.Context, absenceID int64) error
+	GetSchedule(ctx context.Context, userID string) (model.WorkSchedule, error)
+	SetSchedule(ctx context.Context, userID string, schedule model.WorkSchedule) (model.WorkSchedule, error)
+}
+
+// ReviewReassigner moves open reviews away from users that are being deactivated.
+type ReviewReassigner interface {
+	DeactivateReviewers(ctx context.Context, teamName string, userIDs []string, fallbackTeams []string) ([]model.Reassignment, error)
 }
 
 type useCase struct {
-	repo userRepo.Repo
+	repo       userRepo.Repo
+	reassigner ReviewReassigner
 }
 
-func New(repo userRepo.Repo) UseCase {
+func New(repo userRepo.Repo, reassigner ReviewReassigner) UseCase {
 	return &useCase{
-		repo: repo,
+		repo:       repo,
+		reassigner: reassigner,
 	}
 }
 
-func (u *useCase) SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error) {
-	user, err := u.repo.SetIsActive(ctx, userID, isActive)
-	if errors.Is(err, userRepo.ErrUserNotFound) {
-		return model.User{}, ErrUserNotFound
+// SetIsActive on deactivation also reassigns every OPEN review of the user, see ReviewReassigner.
+func (u *useCase) SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, []model.Reassignment, error) {
+	if isActive {
+		user, err := u.repo.SetIsActive(ctx, userID, isActive)
+		if errors.Is(err, userRepo.ErrUserNotFound) {
+			return model.User{}, nil, ErrUserNotFound
+		}
+		return user, []model.Reassignment{}, err
 	}
-	return user, err
+
+	if _, err := u.GetByID(ctx, userID); err != nil {
+		return model.User{}, nil, err
+	}
+	reassignments, err := u.reassigner.DeactivateReviewers(ctx, "", []string{userID}, nil)
+	if err != nil {
+		return model.User{}, nil, err
+	}
+	user, err := u.GetByID(ctx, userID)
+	return user, reassignments, err
 }
 
 func (u *useCase) GetByID(ctx context.Context, userID string) (model.User, error) {
@@ -42,3 +79,46 @@ func (u *useCase) GetByID(ctx context.Context, userID string) (model.User, error
 	}
 	return user, err
 }
+
+func (u *useCase) GetSkills(ctx context.Context, userID string) ([]model.Skill, error) {
+	if _, err := u.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	skills, err := u.repo.GetSkills(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if skills[userID] == nil {
+		return []model.Skill{}, nil
+	}
+	return skills[userID], nil
+}
+
+// SetSkills replaces the skills of the user. Tags are stored normalized, see model.NormalizeTag.
+func (u *useCase) SetSkills(ctx context.Context, userID string, skills []model.Skill) ([]model.Skill, error) {
+	seen := make(map[string]bool, len(skills))
+	normalized := make([]model.Skill, len(skills))
+	for i, skill := range skills {
+		tag := model.NormalizeTag(skill.Tag)
+		switch {
+		case tag == "":
+			return nil, fmt.Errorf("%w: tag is empty", ErrInvalidSkills)
+		case len(tag) > maxTagLength:
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidSkills, tag, maxTagLength)
+		case seen[tag]:
+			return nil, fmt.Errorf("%w: duplicate tag %q", ErrInvalidSkills, tag)
+		case skill.Level < 0 || skill.Level > model.MaxSkillLevel:
+			return nil, fmt.Errorf("%w: level of %q must be between 1 and %d", ErrInvalidSkills, tag, model.MaxSkillLevel)
+		}
+		seen[tag] = true
+		normalized[i] = model.Skill{Tag: tag, Level: skill.Level}
+	}
+	err := u.repo.SetSkills(ctx, userID, normalized)
+	if errors.Is(err, userRepo.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.GetSkills(ctx, userID)
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	webhookRepo "github.com/doverlof/avito_help/internal/client/repo/webhook"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+var (
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidFilter        = errors.New("invalid delivery filter")
+)
+
+type UseCase interface {
+	Create(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	List(ctx context.Context) ([]model.WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+}
+
+type useCase struct {
+	repo webhookRepo.Repo
+}
+
+func New(repo webhookRepo.Repo) UseCase {
+	return &useCase{
+		repo: repo,
+	}
+}
+
+func (u *useCase) Create(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	return u.repo.Create(ctx, subscription)
+}
+
+func validateSubscription(subscription model.WebhookSubscription) error {
+	parsed, err := url.Parse(subscription.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	if subscription.Secret == "" {
+		return fmt.Errorf("%w: secret is required", ErrInvalidSubscription)
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !slices.Contains(model.EventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, eventType)
+		}
+	}
+	return nil
+}
+
+func (u *useCase) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	return u.repo.List(ctx)
+}
+
+func (u *useCase) Delete(ctx context.Context, id int64) error {
+	err := u.repo.Delete(ctx, id)
+	if errors.Is(err, webhookRepo.ErrSubscriptionNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (u *useCase) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	switch {
+	case filter.Limit < 0 || filter.Limit > maxDeliveriesLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, maxDeliveriesLimit)
+	case filter.Limit == 0:
+		filter.Limit = defaultDeliveriesLimit
+	}
+	switch filter.Status {
+	case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, filter.Status)
+	}
+	return u.repo.ListDeliveries(ctx, filter)
+}
@@ -0,0 +1,192 @@
+// Package webhook delivers pull request events queued in the webhook outbox to subscribers.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/health"
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	AttemptHeader   = "X-Webhook-Attempt"
+)
+
+// Store is the outbox the dispatcher works through.
+type Store interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, attempt int, statusCode int) error
+	Reschedule(ctx context.Context, id int64, attempt int, statusCode int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, attempt int, statusCode int, lastError string) error
+}
+
+type Dispatcher struct {
+	store     Store
+	client    *http.Client
+	cfg       config.WebhookConfig
+	component *health.Component
+}
+
+func NewDispatcher(cfg config.WebhookConfig, store Store, component *health.Component) *Dispatcher {
+	return &Dispatcher{
+		store:     store,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		cfg:       cfg,
+		component: component,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		_, err := d.DeliverDue(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("webhook dispatcher:", err)
+		}
+		d.component.Ran(err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends up to BatchSize due deliveries and returns how many were attempted. Every delivery
+// is claimed right before it is sent, so its lease only has to outlast one request.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	for n := 0; n < d.cfg.BatchSize; n++ {
+		deliveries, err := d.store.Claim(ctx, 1, 2*d.cfg.Timeout)
+		if err != nil || len(deliveries) == 0 {
+			return n, err
+		}
+		if err = d.deliver(ctx, deliveries[0]); err != nil {
+			return n + 1, err
+		}
+	}
+	return d.cfg.BatchSize, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) error {
+	statusCode, err := d.send(ctx, delivery)
+	switch {
+	case err == nil:
+		return d.store.MarkDelivered(ctx, delivery.ID, delivery.Attempts, statusCode)
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		return d.store.MarkFailed(ctx, delivery.ID, delivery.Attempts, statusCode, err.Error())
+	}
+	next := time.Now().Add(Backoff(d.cfg.BaseBackoff, d.cfg.MaxBackoff, delivery.Attempts))
+	return d.store.Reschedule(ctx, delivery.ID, delivery.Attempts, statusCode, err.Error(), next)
+}
+
+// Backoff returns the delay before the next attempt: base doubled after every failed attempt, capped by max.
+func Backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// Payload is the JSON body of a webhook request.
+type Payload struct {
+	DeliveryID int64        `json:"delivery_id"`
+	Event      PayloadEvent `json:"event"`
+}
+
+type PayloadEvent struct {
+	EventID       int64     `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	PullRequestID string    `json:"pull_request_id"`
+	Actor         string    `json:"actor,omitempty"`
+	ReviewerID    string    `json:"reviewer_id,omitempty"`
+	OldReviewerID string    `json:"old_reviewer_id,omitempty"`
+	FromStatus    string    `json:"from_status,omitempty"`
+	ToStatus      string    `json:"to_status,omitempty"`
+	Verdict       string    `json:"verdict,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newPayload(delivery model.WebhookDelivery) Payload {
+	event := delivery.Event
+	return Payload{
+		DeliveryID: delivery.ID,
+		Event: PayloadEvent{
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			PullRequestID: event.PullRequestID,
+			Actor:         event.Actor,
+			ReviewerID:    event.ReviewerID,
+			OldReviewerID: event.OldReviewerID,
+			FromStatus:    string(event.FromStatus),
+			ToStatus:      string(event.ToStatus),
+			Verdict:       string(event.Verdict),
+			Reason:        event.Reason,
+			CreatedAt:     event.CreatedAt,
+		},
+	}
+}
+
+// Sign returns the signature header value: hex HMAC-SHA256 of the body keyed with the subscription secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches the body, receivers can use it to check requests.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// send posts the delivery and returns the response status code. Any non-2xx answer is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery model.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(newPayload(delivery))
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, body))
+	req.Header.Set(EventHeader, string(delivery.Event.Type))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(AttemptHeader, strconv.Itoa(delivery.Attempts))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
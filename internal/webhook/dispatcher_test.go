@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/config"
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox mimics the outbox table: Claim counts the attempt and leases the delivery.
+type memoryOutbox struct {
+	mu         sync.Mutex
+	deliveries []*model.WebhookDelivery
+}
+
+func (o *memoryOutbox) Claim(_ context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res := make([]model.WebhookDelivery, 0)
+	for _, d := range o.deliveries {
+		if len(res) == limit {
+			break
+		}
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			d.Attempts++
+			d.NextAttemptAt = time.Now().Add(lease)
+			res = append(res, *d)
+		}
+	}
+	return res, nil
+}
+
+func (o *memoryOutbox) find(id int64) *model.WebhookDelivery {
+	for _, d := range o.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// claimed returns the delivery unless it was claimed again since attempt, like the conditional update does.
+func (o *memoryOutbox) claimed(id int64, attempt int) *model.WebhookDelivery {
+	d := o.find(id)
+	if d.Attempts != attempt || d.Status != model.DeliveryPending {
+		return &model.WebhookDelivery{}
+	}
+	return d
+}
+
+func (o *memoryOutbox) MarkDelivered(_ context.Context, id int64, attempt int, statusCode int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d := o.claimed(id, attempt)
+	d.Status, d.LastStatusCode, d.LastError = model.DeliveryDelivered, statusCode, ""
+	return nil
+}
+
+func (o *memoryOutbox) Reschedule(_ context.Context, id int64, attempt int, statusCode int, lastError string, nextAttemptAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d := o.claimed(id, attempt)
+	d.LastStatusCode, d.LastError, d.NextAttemptAt = statusCode, lastError, nextAttemptAt
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(_ context.Context, id int64, attempt int, statusCode int, lastError string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d := o.claimed(id, attempt)
+	d.Status, d.LastStatusCode, d.LastError = model.DeliveryFailed, statusCode, lastError
+	return nil
+}
+
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		BatchSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	}
+}
+
+func newDelivery(url string) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:     1,
+		URL:    url,
+		Secret: "s3cret",
+		Status: model.DeliveryPending,
+		Event: model.PullRequestEvent{
+			ID:            7,
+			PullRequestID: "pr-1",
+			Type:          model.EventReassigned,
+			ReviewerID:    "u2",
+			OldReviewerID: "u1",
+		},
+	}
+}
+
+// deliverUntilSettled runs the dispatcher until the delivery leaves PENDING.
+func deliverUntilSettled(t *testing.T, d *Dispatcher, outbox *memoryOutbox) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		return outbox.deliveries[0].Status != model.DeliveryPending
+	}, time.Second, 2*time.Millisecond)
+}
+
+func TestDispatcher_RetriesAndSigns(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.True(t, Verify("s3cret", body, r.Header.Get(SignatureHeader)))
+		assert.Equal(t, "REASSIGNED", r.Header.Get(EventHeader))
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "pr-1", payload.Event.PullRequestID)
+		assert.Equal(t, "u1", payload.Event.OldReviewerID)
+
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	outbox := &memoryOutbox{deliveries: []*model.WebhookDelivery{newDelivery(receiver.URL)}}
+	deliverUntilSettled(t, NewDispatcher(testConfig(), outbox, nil), outbox)
+
+	delivery := outbox.deliveries[0]
+	assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	outbox := &memoryOutbox{deliveries: []*model.WebhookDelivery{newDelivery(receiver.URL)}}
+	deliverUntilSettled(t, NewDispatcher(testConfig(), outbox, nil), outbox)
+
+	delivery := outbox.deliveries[0]
+	assert.Equal(t, model.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusBadGateway, delivery.LastStatusCode)
+}
+
+func TestDispatcher_KeepsReclaimedDelivery(t *testing.T) {
+	var outbox *memoryOutbox
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the lease runs out during the request and another instance claims the delivery
+		outbox.mu.Lock()
+		outbox.deliveries[0].NextAttemptAt = time.Now()
+		outbox.mu.Unlock()
+		_, err := outbox.Claim(context.Background(), 1, time.Minute)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	outbox = &memoryOutbox{deliveries: []*model.WebhookDelivery{newDelivery(receiver.URL)}}
+
+	n, err := NewDispatcher(testConfig(), outbox, nil).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	delivery := outbox.deliveries[0]
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.LastError, "the late report of the first claim is dropped")
+	assert.True(t, delivery.NextAttemptAt.After(time.Now().Add(30*time.Second)), "the lease of the second claim stays")
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, Backoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 4*time.Second, Backoff(time.Second, time.Minute, 3))
+	assert.Equal(t, time.Minute, Backoff(time.Second, time.Minute, 10))
+}
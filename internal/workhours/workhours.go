@@ -0,0 +1,136 @@
+// Package workhours does calendar math over model.WorkSchedule: whether someone is at work,
+// when they will be, and when a given amount of working time runs out.
+package workhours
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/model"
+)
+
+var locations sync.Map
+
+// Location loads an IANA time zone such as `Europe/Belgrade`, caching the result.
+func Location(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// ParseClock parses a wall clock time like `09:30` into minutes after midnight, `24:00` is the end of the day.
+func ParseClock(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock formats minutes after midnight as `HH:MM`.
+func FormatClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// Validate checks that the schedule can be used: a known time zone, start before end within one day
+// and at least one distinct working day.
+func Validate(s model.WorkSchedule) error {
+	if _, err := Location(s.Timezone); s.Timezone == "" || err != nil {
+		return fmt.Errorf("unknown time zone %q", s.Timezone)
+	}
+	if s.StartMinute < 0 || s.EndMinute > 24*60 || s.StartMinute >= s.EndMinute {
+		return fmt.Errorf("working hours must start before they end within one day")
+	}
+	if len(s.Weekdays) == 0 {
+		return fmt.Errorf("at least one working day is required")
+	}
+	for i, day := range s.Weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("unknown weekday %d", day)
+		}
+		if slices.Contains(s.Weekdays[:i], day) {
+			return fmt.Errorf("duplicate weekday %s", day)
+		}
+	}
+	return nil
+}
+
+// Next returns the earliest moment not before t inside working hours, t itself when the user is working.
+// Without a schedule (or with an invalid one) it returns t.
+func Next(s model.WorkSchedule, t time.Time) time.Time {
+	start, _, ok := period(s, t)
+	if !ok {
+		return t
+	}
+	return start
+}
+
+// Within reports whether t falls into working hours.
+func Within(s model.WorkSchedule, t time.Time) bool {
+	return Next(s, t).Equal(t)
+}
+
+// Until returns how long it is from t to the start of working hours, 0 when the user is working.
+func Until(s model.WorkSchedule, t time.Time) time.Duration {
+	return Next(s, t).Sub(t)
+}
+
+// Add returns the moment when d of working time has passed since from, skipping nights, weekends
+// and whatever else is outside the schedule. Without a schedule it is from.Add(d).
+func Add(s model.WorkSchedule, from time.Time, d time.Duration) time.Time {
+	t := from
+	for {
+		start, end, ok := period(s, t)
+		if !ok {
+			return t.Add(d)
+		}
+		if d <= end.Sub(start) {
+			return start.Add(d)
+		}
+		d -= end.Sub(start)
+		t = end
+	}
+}
+
+// period returns the working period containing t or the first one after it, with start clamped to t.
+func period(s model.WorkSchedule, t time.Time) (time.Time, time.Time, bool) {
+	if s.IsZero() || Validate(s) != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	loc, _ := Location(s.Timezone)
+	local := t.In(loc)
+	// A week and a day always contains a working day that ends after t.
+	for i := 0; i <= 7; i++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, loc)
+		if !slices.Contains(s.Weekdays, day.Weekday()) {
+			continue
+		}
+		start := atMinute(day, s.StartMinute)
+		end := atMinute(day, s.EndMinute)
+		if !t.Before(end) {
+			continue
+		}
+		if t.After(start) {
+			start = t
+		}
+		return start, end, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// atMinute returns the wall clock time minute minutes after midnight of day, so that 09:00 stays 09:00
+// across daylight saving changes.
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
@@ -0,0 +1,84 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doverlof/avito_help/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func belgrade(t *testing.T, value string) time.Time {
+	t.Helper()
+	loc, err := Location("Europe/Belgrade")
+	require.NoError(t, err)
+	res, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	require.NoError(t, err)
+	return res
+}
+
+func TestNextAndWithin(t *testing.T) {
+	s := model.WorkSchedule{Timezone: "Europe/Belgrade", StartMinute: 9 * 60, EndMinute: 18 * 60, Weekdays: weekdays}
+
+	// Wednesday 11:00 in Belgrade.
+	assert.True(t, Within(s, belgrade(t, "2025-03-12 11:00")))
+	assert.Equal(t, time.Duration(0), Until(s, belgrade(t, "2025-03-12 11:00")))
+
+	// Wednesday 19:00 in Moscow is 17:00 in Belgrade.
+	msk, err := Location("Europe/Moscow")
+	require.NoError(t, err)
+	assert.True(t, Within(s, time.Date(2025, 3, 12, 19, 0, 0, 0, msk)))
+
+	// Friday evening waits for Monday morning.
+	assert.Equal(t, belgrade(t, "2025-03-17 09:00"), Next(s, belgrade(t, "2025-03-14 18:00")))
+	assert.Equal(t, 63*time.Hour, Until(s, belgrade(t, "2025-03-14 18:00")))
+}
+
+func TestAddSkipsNonWorkingTime(t *testing.T) {
+	s := model.WorkSchedule{Timezone: "Europe/Belgrade", StartMinute: 9 * 60, EndMinute: 18 * 60, Weekdays: weekdays}
+
+	assert.Equal(t, belgrade(t, "2025-03-12 15:00"), Add(s, belgrade(t, "2025-03-12 11:00"), 4*time.Hour))
+	// 2h left on Friday, the other 2h on Monday.
+	assert.Equal(t, belgrade(t, "2025-03-17 11:00"), Add(s, belgrade(t, "2025-03-14 16:00"), 4*time.Hour))
+	// Opened at night: the clock starts in the morning.
+	assert.Equal(t, belgrade(t, "2025-03-13 10:00"), Add(s, belgrade(t, "2025-03-12 23:00"), time.Hour))
+	// Across the switch to summer time working hours stay 09:00-18:00 local.
+	assert.Equal(t, belgrade(t, "2025-03-31 10:00"), Add(s, belgrade(t, "2025-03-28 17:00"), 2*time.Hour))
+}
+
+func TestNoScheduleMeansAlwaysWorking(t *testing.T) {
+	now := time.Date(2025, 3, 15, 3, 0, 0, 0, time.UTC)
+	assert.True(t, Within(model.WorkSchedule{}, now))
+	assert.Equal(t, now.Add(time.Hour), Add(model.WorkSchedule{}, now, time.Hour))
+}
+
+func TestValidate(t *testing.T) {
+	valid := model.WorkSchedule{Timezone: "Asia/Almaty", StartMinute: 10 * 60, EndMinute: 19 * 60, Weekdays: weekdays}
+	require.NoError(t, Validate(valid))
+
+	for name, s := range map[string]model.WorkSchedule{
+		"unknown zone":  {Timezone: "Mars/Olympus", StartMinute: 0, EndMinute: 60, Weekdays: weekdays},
+		"empty zone":    {StartMinute: 0, EndMinute: 60, Weekdays: weekdays},
+		"overnight":     {Timezone: "UTC", StartMinute: 22 * 60, EndMinute: 6 * 60, Weekdays: weekdays},
+		"no days":       {Timezone: "UTC", StartMinute: 0, EndMinute: 60},
+		"duplicate day": {Timezone: "UTC", StartMinute: 0, EndMinute: 60, Weekdays: []time.Weekday{time.Monday, time.Monday}},
+	} {
+		assert.Error(t, Validate(s), name)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for value, want := range map[string]int{"00:00": 0, "09:30": 570, "24:00": 1440} {
+		got, err := ParseClock(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+		assert.Equal(t, value, FormatClock(got))
+	}
+	for _, value := range []string{"25:00", "24:30", "12:60", "noon", "-1:00"} {
+		_, err := ParseClock(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS team_settings (
+                                             team_name VARCHAR(255) NOT NULL PRIMARY KEY,
+                                             min_reviewers INTEGER NOT NULL DEFAULT 1 CHECK (min_reviewers >= 0),
+                                             max_reviewers INTEGER NOT NULL DEFAULT 2 CHECK (max_reviewers >= min_reviewers),
+                                             allow_inactive_author BOOLEAN NOT NULL DEFAULT true,
+                                             strategy VARCHAR(32),
+                                             updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                             FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE
+);
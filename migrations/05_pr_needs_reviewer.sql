@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS needs_reviewer BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_pr_needs_reviewer ON pull_requests(needs_reviewer) WHERE needs_reviewer = true;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+                                              team_name VARCHAR(255) NOT NULL,
+                                              fallback_team_name VARCHAR(255) NOT NULL,
+                                              position INTEGER NOT NULL,
+                                              PRIMARY KEY (team_name, fallback_team_name),
+                                              UNIQUE (team_name, position),
+                                              CHECK (team_name <> fallback_team_name),
+                                              FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE,
+                                              FOREIGN KEY (fallback_team_name) REFERENCES teams(team_name) ON DELETE CASCADE
+);
+
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS is_fallback BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,14 @@
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0);
+
+CREATE TABLE IF NOT EXISTS pr_reviews (
+                                          review_id BIGSERIAL PRIMARY KEY,
+                                          pull_request_id VARCHAR(255) NOT NULL,
+                                          reviewer_id VARCHAR(255) NOT NULL,
+                                          verdict VARCHAR(32) NOT NULL CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+                                          comment TEXT,
+                                          created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                          FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+                                          FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_pr_reviewer ON pr_reviews(pull_request_id, reviewer_id, created_at DESC);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS pr_events (
+                                         event_id BIGSERIAL PRIMARY KEY,
+                                         pull_request_id VARCHAR(255) NOT NULL,
+                                         event_type VARCHAR(32) NOT NULL,
+                                         actor VARCHAR(255),
+                                         reviewer_id VARCHAR(255),
+                                         old_reviewer_id VARCHAR(255),
+                                         from_status VARCHAR(20),
+                                         to_status VARCHAR(20),
+                                         verdict VARCHAR(32),
+                                         reason VARCHAR(64),
+                                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                         FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pull_request_id, event_id);
+
+CREATE OR REPLACE FUNCTION pr_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'pr_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_events_no_update ON pr_events;
+CREATE TRIGGER pr_events_no_update BEFORE UPDATE ON pr_events
+    FOR EACH ROW EXECUTE FUNCTION pr_events_append_only();
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+                                                     subscription_id BIGSERIAL PRIMARY KEY,
+                                                     url TEXT NOT NULL,
+                                                     secret TEXT NOT NULL,
+                                                     event_types TEXT[] NOT NULL DEFAULT '{}',
+                                                     is_active BOOLEAN NOT NULL DEFAULT true,
+                                                     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Outbox: rows are written in the same transaction as the pr_events they deliver.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  delivery_id BIGSERIAL PRIMARY KEY,
+                                                  subscription_id BIGINT NOT NULL,
+                                                  event_id BIGINT NOT NULL,
+                                                  status VARCHAR(16) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+                                                  attempts INTEGER NOT NULL DEFAULT 0,
+                                                  last_status_code INTEGER,
+                                                  last_error TEXT,
+                                                  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                  delivered_at TIMESTAMP WITH TIME ZONE,
+                                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                  FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(subscription_id) ON DELETE CASCADE,
+                                                  FOREIGN KEY (event_id) REFERENCES pr_events(event_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, delivery_id DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS github_users (
+                                            login VARCHAR(255) NOT NULL PRIMARY KEY,
+                                            user_id VARCHAR(255) NOT NULL UNIQUE,
+                                            FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Processed X-GitHub-Delivery ids, so that redelivered webhooks are applied once.
+CREATE TABLE IF NOT EXISTS github_deliveries (
+                                                 delivery_id VARCHAR(255) NOT NULL PRIMARY KEY,
+                                                 event VARCHAR(64) NOT NULL,
+                                                 action VARCHAR(64),
+                                                 received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- One row per pull request whose reviewers have to be mirrored to the code host.
+-- revision grows with every assignment change, so a sync that raced a change doesn't mark the row SYNCED.
+CREATE TABLE IF NOT EXISTS code_host_syncs (
+                                               pull_request_id VARCHAR(255) NOT NULL PRIMARY KEY,
+                                               status VARCHAR(16) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SYNCED', 'FAILED', 'SKIPPED')),
+                                               revision BIGINT NOT NULL DEFAULT 1,
+                                               attempts INTEGER NOT NULL DEFAULT 0,
+                                               synced_reviewers TEXT[] NOT NULL DEFAULT '{}',
+                                               last_error TEXT,
+                                               next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               synced_at TIMESTAMP WITH TIME ZONE,
+                                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_host_syncs_pending ON code_host_syncs(next_attempt_at) WHERE status = 'PENDING';
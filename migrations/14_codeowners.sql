@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS team_codeowners (
+                                               team_name VARCHAR(255) NOT NULL,
+                                               repository VARCHAR(255) NOT NULL,
+                                               content TEXT NOT NULL,
+                                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               PRIMARY KEY (team_name, repository),
+                                               FOREIGN KEY (team_name) REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS is_code_owner BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_skills (
+                                           user_id VARCHAR(255) NOT NULL,
+                                           tag VARCHAR(64) NOT NULL,
+                                           level SMALLINT CHECK (level BETWEEN 1 AND 5),
+                                           PRIMARY KEY (user_id, tag),
+                                           FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_skills_tag ON user_skills(tag);
+
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS labels TEXT[] NOT NULL DEFAULT '{}';
+-- Tags of the reviewer that matched the pull request labels when the reviewer was picked.
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS matched_tags TEXT[] NOT NULL DEFAULT '{}';
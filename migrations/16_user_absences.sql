@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS user_absences (
+                                             absence_id BIGSERIAL PRIMARY KEY,
+                                             user_id VARCHAR(255) NOT NULL,
+                                             starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                             ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                             reason TEXT NOT NULL DEFAULT '',
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                             CHECK (ends_at > starts_at),
+                                             FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_user_ends ON user_absences(user_id, ends_at);
+
+-- Members whose absence starts within this many hours are not picked as reviewers, 0 only skips current absences.
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS absence_lookahead_hours INTEGER NOT NULL DEFAULT 0 CHECK (absence_lookahead_hours >= 0);
@@ -0,0 +1,10 @@
+-- Working hours of a user, weekdays are ISO: 1 is Monday, 7 is Sunday.
+CREATE TABLE IF NOT EXISTS user_schedules (
+                                              user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+                                              timezone VARCHAR(64) NOT NULL,
+                                              work_start TIME NOT NULL,
+                                              work_end TIME NOT NULL CHECK (work_end > work_start),
+                                              weekdays SMALLINT[] NOT NULL DEFAULT '{1,2,3,4,5}',
+                                              updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                              FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- Working hours a reviewer has to start the review in, 0 disables the SLA.
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS review_sla_hours INTEGER NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0);
+
+-- When the review has to start by, NULL when the author's team had no SLA at assignment time.
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_due_at ON pr_reviewers(due_at) WHERE due_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Set by the reminder worker, reset when the reviewer is replaced.
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_assigned_at ON pr_reviewers(assigned_at) WHERE escalated_at IS NULL;
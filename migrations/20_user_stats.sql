@@ -0,0 +1,46 @@
+-- Counters behind GET /stats/users, kept up to date by the transactions that change
+-- pull requests and their reviewers. Rebuild with `backend-app recompute-stats`.
+CREATE TABLE IF NOT EXISTS user_stats (
+                                          user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+                                          total_review_assignments INTEGER NOT NULL DEFAULT 0,
+                                          open_review_assignments INTEGER NOT NULL DEFAULT 0,
+                                          merged_review_assignments INTEGER NOT NULL DEFAULT 0,
+                                          closed_review_assignments INTEGER NOT NULL DEFAULT 0,
+                                          total_authored_prs INTEGER NOT NULL DEFAULT 0,
+                                          open_authored_prs INTEGER NOT NULL DEFAULT 0,
+                                          merged_authored_prs INTEGER NOT NULL DEFAULT 0,
+                                          closed_authored_prs INTEGER NOT NULL DEFAULT 0,
+                                          updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                          FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+INSERT INTO user_stats (
+    user_id,
+    total_review_assignments, open_review_assignments, merged_review_assignments, closed_review_assignments,
+    total_authored_prs, open_authored_prs, merged_authored_prs, closed_authored_prs
+)
+SELECT
+    u.user_id,
+    COALESCE(rv.total, 0), COALESCE(rv.open, 0), COALESCE(rv.merged, 0), COALESCE(rv.closed, 0),
+    COALESCE(au.total, 0), COALESCE(au.open, 0), COALESCE(au.merged, 0), COALESCE(au.closed, 0)
+FROM users u
+LEFT JOIN (
+    SELECT r.reviewer_id AS user_id,
+           COUNT(*) AS total,
+           COUNT(*) FILTER (WHERE p.status = 'OPEN') AS open,
+           COUNT(*) FILTER (WHERE p.status = 'MERGED') AS merged,
+           COUNT(*) FILTER (WHERE p.status = 'CLOSED') AS closed
+    FROM pr_reviewers r
+    JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+    GROUP BY r.reviewer_id
+) rv ON rv.user_id = u.user_id
+LEFT JOIN (
+    SELECT author_id AS user_id,
+           COUNT(*) AS total,
+           COUNT(*) FILTER (WHERE status = 'OPEN') AS open,
+           COUNT(*) FILTER (WHERE status = 'MERGED') AS merged,
+           COUNT(*) FILTER (WHERE status = 'CLOSED') AS closed
+    FROM pull_requests
+    GROUP BY author_id
+) au ON au.user_id = u.user_id
+ON CONFLICT (user_id) DO NOTHING;
@@ -0,0 +1,10 @@
+-- Versions of the applied migrations, checked by GET /ready. Every new migration
+-- ends with its own INSERT and bumps repo/health.SchemaVersion.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+                                                 version INTEGER NOT NULL PRIMARY KEY,
+                                                 applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schema_migrations (version)
+SELECT generate_series(1, 21)
+ON CONFLICT (version) DO NOTHING;
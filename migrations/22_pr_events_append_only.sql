@@ -0,0 +1,20 @@
+-- Events are never changed or deleted. The only exception is the ON DELETE CASCADE of a deleted pull
+-- request: its row is already gone when the cascade reaches the events, so its history goes with it.
+CREATE OR REPLACE FUNCTION pr_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (
+        SELECT 1 FROM pull_requests WHERE pull_request_id = OLD.pull_request_id
+    ) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'pr_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_events_no_update ON pr_events;
+DROP TRIGGER IF EXISTS pr_events_append_only ON pr_events;
+CREATE TRIGGER pr_events_append_only BEFORE UPDATE OR DELETE ON pr_events
+    FOR EACH ROW EXECUTE FUNCTION pr_events_append_only();
+
+INSERT INTO schema_migrations (version) VALUES (22)
+ON CONFLICT (version) DO NOTHING;